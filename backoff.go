@@ -0,0 +1,29 @@
+package peplink
+
+import (
+	"math/rand"
+	"time"
+)
+
+// backoff computes exponential delays with jitter
+type backoff struct {
+	min time.Duration // delay before the first retry
+	max time.Duration // upper bound of the delay
+}
+
+// duration returns the delay before the given retry attempt. Attempts start from 1
+// The result is randomized in [d/2, d) to avoid synchronized retries
+func (b backoff) duration(attempt int) time.Duration {
+	d := b.min
+	for i := 1; i < attempt && d < b.max; i++ {
+		d *= 2
+	}
+	if d > b.max {
+		d = b.max
+	}
+	if d <= 1 {
+		return d
+	}
+
+	return d/2 + time.Duration(rand.Int63n(int64(d/2)))
+}
@@ -0,0 +1,29 @@
+package peplink
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBackoff_duration(t *testing.T) {
+	b := backoff{min: 100 * time.Millisecond, max: time.Second}
+	tests := []struct {
+		attempt int
+		want    time.Duration // upper bound. Jittered value is in [want/2, want)
+	}{
+		{1, 100 * time.Millisecond},
+		{2, 200 * time.Millisecond},
+		{4, 800 * time.Millisecond},
+		{5, time.Second},
+		{100, time.Second},
+	}
+	for _, tt := range tests {
+		for i := 0; i < 100; i++ {
+			got := b.duration(tt.attempt)
+			require.GreaterOrEqual(t, got, tt.want/2, "attempt %d", tt.attempt)
+			require.Less(t, got, tt.want, "attempt %d", tt.attempt)
+		}
+	}
+}
@@ -0,0 +1,169 @@
+package peplink
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// errUnknownFirmware is matched when the device doesn't report a firmware version the client can parse
+var errUnknownFirmware = errors.New("unknown firmware")
+
+// Capability is the feature of the device API which depends on the firmware
+type Capability string
+
+const (
+	// CapabilityCellular is the "cellular" WAN type and object which replaced "gobi"
+	CapabilityCellular Capability = "cellular"
+	// CapabilityRemoteSIMAPN is the APN, username and password of the remote SIM
+	CapabilityRemoteSIMAPN Capability = "remote-sim-apn"
+)
+
+// capabilityMinVersion is the first firmware with the capability
+var capabilityMinVersion = map[Capability]Version{
+	CapabilityCellular:     {Major: 8, Minor: 0, Patch: 1},
+	CapabilityRemoteSIMAPN: {Major: 8, Minor: 1, Patch: 1},
+}
+
+// Capabilities is the set of the capabilities of the device
+type Capabilities map[Capability]bool
+
+// Has reports whether the device has the capability
+func (c Capabilities) Has(capability Capability) bool {
+	return c[capability]
+}
+
+// List returns the capabilities sorted by name
+func (c Capabilities) List() []Capability {
+	list := make([]Capability, 0, len(c))
+	for capability, ok := range c {
+		if ok {
+			list = append(list, capability)
+		}
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i] < list[j] })
+
+	return list
+}
+
+// capabilitiesOf returns the capabilities of the firmware
+func capabilitiesOf(v Version) Capabilities {
+	caps := Capabilities{}
+	for capability, first := range capabilityMinVersion {
+		if !v.Before(first.Major, first.Minor, first.Patch) {
+			caps[capability] = true
+		}
+	}
+
+	return caps
+}
+
+// Capabilities returns the capabilities of the device firmware
+// The firmware is fetched on the first call and cached
+func (c *Client) Capabilities(ctx context.Context) (Capabilities, error) {
+	v, err := c.runningFirmware(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return capabilitiesOf(v), nil
+}
+
+// firmwareDetection is shared by all callers waiting for the same firmware detection
+type firmwareDetection struct {
+	done      chan struct{}
+	version   Version
+	err       error
+	abandoned bool // the context of the detecting caller ended. The waiters detect again
+}
+
+// runningFirmware returns the cached version of the firmware in use
+// Concurrent callers share a single detection and wait for it until their own ctx is done
+func (c *Client) runningFirmware(ctx context.Context) (Version, error) {
+	for {
+		c.firmwareMu.Lock()
+		if c.firmware != nil {
+			v := *c.firmware
+			c.firmwareMu.Unlock()
+			return v, nil
+		}
+		if d := c.detecting; d != nil {
+			c.firmwareMu.Unlock()
+			select {
+			case <-d.done:
+			case <-ctx.Done():
+				return Version{}, ctx.Err()
+			}
+			if d.abandoned && ctx.Err() == nil {
+				continue
+			}
+			return d.version, d.err
+		}
+		d := &firmwareDetection{done: make(chan struct{})}
+		c.detecting = d
+		c.firmwareMu.Unlock()
+
+		d.version, d.err = c.detectFirmware(ctx)
+		d.abandoned = d.err != nil && ctx.Err() != nil
+
+		c.firmwareMu.Lock()
+		if c.detecting == d {
+			c.detecting = nil
+			if d.err == nil {
+				c.firmware = &d.version
+			}
+		}
+		c.firmwareMu.Unlock()
+		close(d.done)
+
+		return d.version, d.err
+	}
+}
+
+// detectFirmware returns the version of the firmware in use
+func (c *Client) detectFirmware(ctx context.Context) (Version, error) {
+	slots, err := c.Firmwares(ctx)
+	if err != nil {
+		return Version{}, fmt.Errorf("failed to detect firmware: %w", err)
+	}
+	slot, ok := slots.InUse()
+	if !ok {
+		return Version{}, fmt.Errorf("failed to detect firmware: %w: no firmware in use", errUnknownFirmware)
+	}
+	if slot.VersionErr != nil {
+		return Version{}, fmt.Errorf("failed to detect firmware: %w in slot %d: %s", errUnknownFirmware, slot.ID, slot.VersionErr)
+	}
+
+	return slot.Version, nil
+}
+
+// forgetFirmware drops the cached firmware. Must be called when the device may run another firmware
+// The result of the in-flight detection isn't cached
+func (c *Client) forgetFirmware() {
+	c.firmwareMu.Lock()
+	defer c.firmwareMu.Unlock()
+
+	c.firmware = nil
+	c.detecting = nil
+}
+
+// requireCapability returns ErrNotSupported if the firmware of the device doesn't have the capability
+// Endpoint methods call it before sending the request which is bound to fail
+// An unknown firmware lets the request through. The device answers 404 or 501 mapped to ErrNotSupported then
+func (c *Client) requireCapability(ctx context.Context, capability Capability) error {
+	v, err := c.runningFirmware(ctx)
+	if errors.Is(err, errUnknownFirmware) {
+		c.log.Debug("Firmware is unknown, sending the request", "capability", capability, "error", err)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if !capabilitiesOf(v).Has(capability) {
+		first := capabilityMinVersion[capability]
+		return fmt.Errorf("%w: %s requires firmware %s or later, device runs %s", ErrNotSupported, capability, first, v)
+	}
+
+	return nil
+}
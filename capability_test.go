@@ -0,0 +1,107 @@
+package peplink
+
+import (
+	"context"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCapabilitiesOf(t *testing.T) {
+	tests := []struct {
+		version string
+		want    []Capability
+	}{
+		{"7.1.2 build 3000", []Capability{}},
+		{"8.0.0 build 4000", []Capability{}},
+		{"8.0.1 build 4100", []Capability{CapabilityCellular}},
+		{"8.1.1s012 build 4500", []Capability{CapabilityCellular, CapabilityRemoteSIMAPN}},
+		{"8.3.0 build 5229", []Capability{CapabilityCellular, CapabilityRemoteSIMAPN}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.version, func(t *testing.T) {
+			v, err := ParseVersion(tt.version)
+			require.NoError(t, err)
+			require.Equal(t, tt.want, capabilitiesOf(v).List())
+		})
+	}
+}
+
+func TestClient_Capabilities(t *testing.T) {
+	var calls atomic.Int32
+	version := "8.0.0 build 4000"
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/api/info.frw.version", r.URL.Path)
+		calls.Add(1)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"stat": "ok", "response": {"1": {"version": "` + version + `", "bootable": true, "inUse": true}, "order": [1]}}`))
+	})
+
+	caps, err := c.Capabilities(context.Background())
+	require.NoError(t, err)
+	require.False(t, caps.Has(CapabilityCellular))
+
+	err = c.requireCapability(context.Background(), CapabilityCellular)
+	require.ErrorIs(t, err, ErrNotSupported)
+	require.ErrorContains(t, err, "requires firmware 8.0.1 or later, device runs 8.0.0 build 4000")
+	require.Equal(t, int32(1), calls.Load(), "firmware must be cached")
+
+	version = "8.3.0 build 5229"
+	c.forgetFirmware()
+	require.NoError(t, c.requireCapability(context.Background(), CapabilityRemoteSIMAPN))
+	require.Equal(t, int32(2), calls.Load())
+
+	version = "unknown"
+	c.forgetFirmware()
+	_, err = c.Capabilities(context.Background())
+	require.ErrorContains(t, err, `invalid firmware version "unknown"`)
+	require.NoError(t, c.requireCapability(context.Background(), CapabilityCellular), "unknown firmware must let the request through")
+	require.Equal(t, int32(4), calls.Load(), "unparsable firmware must not be cached")
+}
+
+func TestClient_runningFirmwareShared(t *testing.T) {
+	var calls atomic.Int32
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			// Hold the first detection until its caller gives up
+			<-r.Context().Done()
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"stat": "ok", "response": {"1": {"version": "8.3.0 build 5229", "bootable": true, "inUse": true}, "order": [1]}}`))
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	leaderErr := make(chan error, 1)
+	go func() {
+		_, err := c.runningFirmware(ctx)
+		leaderErr <- err
+	}()
+	require.Eventually(t, func() bool { return calls.Load() == 1 }, time.Second, time.Millisecond)
+
+	short, cancelShort := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancelShort()
+	_, err := c.runningFirmware(short)
+	require.ErrorIs(t, err, context.DeadlineExceeded, "waiter must not outlive its own ctx")
+
+	type result struct {
+		v   Version
+		err error
+	}
+	follower := make(chan result, 1)
+	go func() {
+		v, err := c.runningFirmware(context.Background())
+		follower <- result{v, err}
+	}()
+	time.Sleep(20 * time.Millisecond)
+	cancel()
+
+	require.ErrorIs(t, <-leaderErr, context.Canceled)
+	got := <-follower
+	require.NoError(t, got.err, "waiter must detect again when the detecting caller gave up")
+	require.Equal(t, "8.3.0 build 5229", got.v.String())
+	require.Equal(t, int32(2), calls.Load())
+}
@@ -0,0 +1,128 @@
+package peplink
+
+// CellularInfo is the firmware independent view of the cellular WAN
+// Firmware before 8.0.1 reports the cellular WAN as "gobi" with the network in "network",
+// later firmware reports it as "cellular" with the network in "mobileType"
+type CellularInfo struct {
+	MobileType   string        // Network name, e.g. LTE
+	SignalLevel  int           // Signal level [0,5]
+	Signal       SignalObj     // Signal of the primary band
+	Carrier      CarrierObj    // Carrier information
+	SIMs         []SIMGroupObj // SIM information. Empty for USB modems without the gobi details
+	Bands        []BandObj     // Bands of all the RATs
+	CellTower    CellTowerObj  // Cell Tower information. Empty for USB modems without the gobi details
+	IMEI         string        // International Mobile Equipment Identity (IMEI)
+	Manufacturer string        // Module manufacturer
+	Model        string        // Module model
+	Firmware     string        // Module firmware
+}
+
+// CellularInfo returns the cellular details of the WAN
+// caps are the capabilities of the device firmware, see Client.Capabilities. They pick the "cellular" or the "gobi" object
+// If caps is nil the object is guessed from the reported fields
+// false if the WAN is not cellular or modem
+func (s WanStatus) CellularInfo(caps Capabilities) (CellularInfo, bool) {
+	switch cellularType(s, caps) {
+	case "cellular":
+		return gobiCellularInfo(s.Cellular), true
+	case "gobi":
+		return gobiCellularInfo(s.Gobi), true
+	case "modem":
+		return modemCellularInfo(s.Modem), true
+	}
+
+	return CellularInfo{}, false
+}
+
+// cellularType returns the source of the cellular details
+// VirtualType is preferred because it keeps the connection type for the virtual WANs
+func cellularType(s WanStatus, caps Capabilities) string {
+	t := s.VirtualType
+	if t == "" {
+		t = s.Type
+	}
+	if t != "cellular" && t != "gobi" {
+		return t
+	}
+
+	if caps != nil {
+		if caps.Has(CapabilityCellular) {
+			return "cellular"
+		}
+		return "gobi"
+	}
+	if t == "cellular" && isZeroGobi(s.Cellular) && !isZeroGobi(s.Gobi) {
+		return "gobi"
+	}
+
+	return t
+}
+
+func isZeroGobi(g GobiObj) bool {
+	return g.MobileType == "" && g.Network == "" && g.IMEI == "" && len(g.SIM) == 0 && len(g.RAT) == 0 &&
+		g.CellTower == (CellTowerObj{})
+}
+
+func gobiCellularInfo(g GobiObj) CellularInfo {
+	info := CellularInfo{
+		MobileType:   mobileType(g.MobileType, g.Network),
+		SignalLevel:  g.SignalLevel,
+		Carrier:      g.Carrier,
+		SIMs:         g.SIM,
+		CellTower:    g.CellTower,
+		IMEI:         g.IMEI,
+		Manufacturer: g.Manufacturer,
+		Model:        g.Model,
+		Firmware:     g.Firmware,
+	}
+	for _, rat := range g.RAT {
+		info.Bands = append(info.Bands, rat.Band...)
+	}
+	if len(info.Bands) > 0 {
+		info.Signal = info.Bands[0].Signal
+	}
+
+	return info
+}
+
+// modemCellularInfo takes the modem fields and fills the rest from the gobi details of the modem if they are reported
+func modemCellularInfo(m ModemObj) CellularInfo {
+	info := CellularInfo{
+		MobileType:   mobileType(m.MobileType, m.Network),
+		SignalLevel:  m.SignalLevel,
+		Carrier:      m.Carrier,
+		Bands:        m.Band,
+		Manufacturer: m.Manufacturer,
+		Model:        m.Name,
+	}
+	if !isZeroGobi(m.Gobi) {
+		g := gobiCellularInfo(m.Gobi)
+		info.SIMs = g.SIMs
+		info.CellTower = g.CellTower
+		info.IMEI = g.IMEI
+		info.Firmware = g.Firmware
+		if info.MobileType == "" {
+			info.MobileType = g.MobileType
+		}
+		if info.Carrier.Name == "" {
+			info.Carrier = g.Carrier
+		}
+		if len(info.Bands) == 0 {
+			info.Bands = g.Bands
+		}
+	}
+	if len(info.Bands) > 0 {
+		info.Signal = info.Bands[0].Signal
+	}
+
+	return info
+}
+
+// mobileType prefers "mobileType" of fw8.0.1 or later over the deprecated "network"
+func mobileType(mobileType, network string) string {
+	if mobileType != "" {
+		return mobileType
+	}
+
+	return network
+}
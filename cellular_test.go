@@ -0,0 +1,93 @@
+package peplink
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWanStatus_CellularInfo(t *testing.T) {
+	band := BandObj{Name: "LTE Band 3 (1800 MHz)", Channel: 1300, Signal: SignalObj{RSSI: ptr(-43)}}
+	sims := []SIMGroupObj{{Active: true, Iccid: "22222222"}}
+	tests := []struct {
+		name   string
+		status WanStatus
+		caps   Capabilities
+		want   CellularInfo
+		wantOk bool
+	}{
+		{"cellular fw8.0.1 or later",
+			WanStatus{Type: "cellular", VirtualType: "cellular", Cellular: GobiObj{
+				Network: "LTE", MobileType: "LTE-A", SignalLevel: 5, SIM: sims,
+				RAT:       []RatObj{{Band: []BandObj{band}}, {Band: []BandObj{{Name: "5G"}}}},
+				CellTower: CellTowerObj{CellID: 11111}, IMEI: "1111", Model: "Model2",
+			}},
+			capabilitiesOf(Version{Major: 8, Minor: 3}),
+			CellularInfo{MobileType: "LTE-A", SignalLevel: 5, Signal: band.Signal, SIMs: sims,
+				Bands: []BandObj{band, {Name: "5G"}}, CellTower: CellTowerObj{CellID: 11111}, IMEI: "1111", Model: "Model2"},
+			true,
+		},
+		{"gobi before fw8.0.1",
+			WanStatus{Type: "gobi", VirtualType: "gobi", Gobi: GobiObj{
+				Network: "LTE", SignalLevel: 4, SIM: sims, RAT: []RatObj{{Band: []BandObj{band}}},
+				Carrier: CarrierObj{Name: "Carrier2"},
+			}},
+			capabilitiesOf(Version{Major: 7, Minor: 1}),
+			CellularInfo{MobileType: "LTE", SignalLevel: 4, Signal: band.Signal, SIMs: sims,
+				Bands: []BandObj{band}, Carrier: CarrierObj{Name: "Carrier2"}},
+			true,
+		},
+		{"cellular type with gobi object",
+			WanStatus{Type: "cellular", Gobi: GobiObj{Network: "LTE", IMEI: "1111"}},
+			nil,
+			CellularInfo{MobileType: "LTE", IMEI: "1111"},
+			true,
+		},
+		{"usb modem",
+			WanStatus{Type: "modem", VirtualType: "modem", Modem: ModemObj{
+				Name: "USB Modem", Manufacturer: "Example", Network: "3G", SignalLevel: 2, Band: []BandObj{band},
+			}},
+			nil,
+			CellularInfo{MobileType: "3G", SignalLevel: 2, Signal: band.Signal, Bands: []BandObj{band},
+				Manufacturer: "Example", Model: "USB Modem"},
+			true,
+		},
+		{"firmware picks cellular",
+			WanStatus{Type: "cellular", Cellular: GobiObj{MobileType: "5G", IMEI: "2222"}, Gobi: GobiObj{Network: "LTE", IMEI: "1111"}},
+			capabilitiesOf(Version{Major: 8, Minor: 0, Patch: 1}),
+			CellularInfo{MobileType: "5G", IMEI: "2222"},
+			true,
+		},
+		{"firmware picks gobi",
+			WanStatus{Type: "cellular", Cellular: GobiObj{MobileType: "5G", IMEI: "2222"}, Gobi: GobiObj{Network: "LTE", IMEI: "1111"}},
+			capabilitiesOf(Version{Major: 8}),
+			CellularInfo{MobileType: "LTE", IMEI: "1111"},
+			true,
+		},
+		{"usb modem with gobi details",
+			WanStatus{Type: "modem", VirtualType: "modem", Modem: ModemObj{
+				Name: "USB Modem", SignalLevel: 3, Gobi: GobiObj{
+					MobileType: "LTE", SIM: sims, RAT: []RatObj{{Band: []BandObj{band}}}, Carrier: CarrierObj{Name: "Carrier1"},
+					CellTower: CellTowerObj{CellID: 11111}, IMEI: "1111", Firmware: "FW1",
+				},
+			}},
+			nil,
+			CellularInfo{MobileType: "LTE", SignalLevel: 3, Signal: band.Signal, Carrier: CarrierObj{Name: "Carrier1"}, SIMs: sims,
+				Bands: []BandObj{band}, CellTower: CellTowerObj{CellID: 11111}, IMEI: "1111", Model: "USB Modem", Firmware: "FW1"},
+			true,
+		},
+		{"ethernet",
+			WanStatus{Type: "ethernet", VirtualType: "ethernet"},
+			capabilitiesOf(Version{Major: 8, Minor: 3}),
+			CellularInfo{},
+			false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := tt.status.CellularInfo(tt.caps)
+			require.Equal(t, tt.wantOk, ok)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -2,9 +2,13 @@ package peplink
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
+	"net/http/cookiejar"
 	"strconv"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/go-resty/resty/v2"
@@ -12,78 +16,257 @@ import (
 
 // Client for the https://www.peplink.com/ic2-api-doc
 type Client struct {
-	httpClient *resty.Client
-	log        *slog.Logger
+	httpClient   *resty.Client
+	snmp         snmpConfig
+	log          *slog.Logger
+	clientID     string
+	clientSecret string
+	authMode     authMode
+	username     string
+	password     string
+
+	tokenMu        sync.Mutex
+	token          string
+	refreshing     *tokenRefresh // in-flight re-authentication. nil if there is none
+	refreshStatus  TokenRefreshStatus
+	refreshBackoff backoff
+
+	tokenStore TokenStore
+	tokenKey   TokenKey
+
+	closed        atomic.Bool
+	closeMu       sync.Mutex
+	closeDone     bool               // shutdown finished. A failed Close leaves it false so Close can be retried
+	stop          context.CancelFunc // stops the token refresher. nil if it isn't started
+	stopped       chan struct{}      // closed when the token refresher exits
+	revokeOnClose bool
+
+	retry    RetryPolicy
+	throttle *throttle
+
+	firmwareMu sync.Mutex
+	firmware   *Version           // firmware in use. nil until it is detected
+	detecting  *firmwareDetection // in-flight firmware detection. nil if there is none
+
+	rebootedAt  atomic.Int64  // unix nanoseconds of the last Reboot. 0 if WaitReady already saw the device back
+	rebootGrace time.Duration // how long WaitReady waits for the device to go down after Reboot
+	readyPoll   backoff       // delays between the probes of WaitReady
+}
+
+// tokenRefresh is shared by all callers waiting for the same re-authentication
+type tokenRefresh struct {
+	done      chan struct{}
+	err       error
+	abandoned bool // the context of the re-authenticating caller ended. The waiters re-authenticate again
 }
 
 // NewClient creates a new Peplink Client and authenticates against the API
-// Runs token update process in the background
+// Runs token update process in the background until ctx is done or Close is called
 func NewClient(ctx context.Context, opts ...Option) (*Client, error) {
 	options := &options{
 		timeout:           10 * time.Second,
 		httpBasicEndpoint: "http://127.0.0.1:8080",
 		snmpAddress:       "127.0.0.1:161",
 		snmpCommunity:     "public",
+		rebootGrace:       30 * time.Second,
 	}
 
+	var errs []error
 	for _, o := range opts {
-		o(options)
+		err := o(options)
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	errs = append(errs, options.validate())
+	err := errors.Join(errs...)
+	if err != nil {
+		return nil, fmt.Errorf("invalid options: %w", err)
 	}
 
-	rest := resty.New().
-		SetBaseURL(options.httpBasicEndpoint).
-		SetHeader("Content-Type", "application/json").
-		SetHeader("Accept", "application/json").
-		SetTimeout(options.timeout)
+	rest, err := newRestClient(options)
+	if err != nil {
+		return nil, err
+	}
+
+	logger := options.logger
+	if logger == nil {
+		logger = slog.Default()
+	}
 
 	c := &Client{
 		httpClient: rest,
-		log:        slog.Default(),
+		snmp: snmpConfig{
+			address:   options.snmpAddress,
+			community: options.snmpCommunity,
+			timeout:   options.timeout,
+			v3:        options.snmpV3,
+		},
+		log:          logger.With(append([]any{"endpoint", options.httpBasicEndpoint}, options.logAttrs...)...),
+		clientID:     options.httpClientID,
+		clientSecret: options.httpClientSecret,
+		authMode:     options.authMode,
+		username:     options.username,
+		password:     options.password,
+		refreshBackoff: backoff{
+			min: time.Second,
+			max: 5 * time.Minute,
+		},
+		readyPoll: backoff{
+			min: time.Second,
+			max: 10 * time.Second,
+		},
+		rebootGrace:   options.rebootGrace,
+		revokeOnClose: options.revokeOnClose,
+		retry:         options.retry,
+		throttle:      newThrottle(options.throttle),
+		tokenStore:    options.tokenStore,
+		tokenKey: TokenKey{
+			Endpoint: options.httpBasicEndpoint,
+			ClientID: options.httpClientID,
+		},
+	}
+
+	if options.tls.insecureSkipVerify {
+		c.log.Warn("TLS certificate verification of the Peplink API is DISABLED. Connections are open to man-in-the-middle attacks")
 	}
 
-	ttl, err := c.authenticate(context.Background(), options.httpClientID, options.httpClientSecret)
+	if options.snmpOnly {
+		return c, nil
+	}
+
+	// Sessions are renewed on demand when the device rejects them. No refresher is needed
+	if options.authMode == authModeSession {
+		err = c.login(ctx)
+		if err != nil {
+			c.log.Error("Failed to login", "error", err)
+
+			return nil, fmt.Errorf("failed to login: %w", err)
+		}
+
+		return c, nil
+	}
+
+	ttl, err := c.authenticate(ctx, options.httpClientID, options.httpClientSecret)
 	if err != nil {
 		c.log.Error("Failed to authenticate", "error", err)
 
 		return nil, fmt.Errorf("failed to authenticate: %w", err)
 	}
 
+	refreshCtx, stop := context.WithCancel(ctx)
+	c.stop = stop
+	c.stopped = make(chan struct{})
 	go func() {
-		time.Sleep(ttl - 10*time.Minute)
-		err := c.watchToken(ctx, options.httpClientID, options.httpClientSecret)
-		if err != nil {
-			c.log.Error("Failed to watch token", "error", err)
-		}
+		defer close(c.stopped)
+		c.watchToken(refreshCtx, ttl)
 	}()
 
 	return c, nil
 }
 
-func (c *Client) watchToken(ctx context.Context, clientID, clientSecret string) error {
-	c.log.Info("Peplink token refresh goroutine started")
-	defer c.log.Info("Peplink token refresh goroutine stopped")
+// newRestClient builds the HTTP client for the device API from the options
+func newRestClient(options *options) (*resty.Client, error) {
+	var rest *resty.Client
+	if options.httpClient != nil {
+		// Copy to keep the caller's client untouched by the settings below
+		hc := *options.httpClient
+		if hc.Jar == nil {
+			jar, err := cookiejar.New(nil)
+			if err != nil {
+				return nil, fmt.Errorf("failed to create cookie jar: %w", err)
+			}
+			hc.Jar = jar
+		}
+		rest = resty.NewWithClient(&hc)
+	} else {
+		rest = resty.New()
+	}
 
-	ttl, err := c.authenticate(ctx, clientID, clientSecret)
-	if err != nil {
-		return fmt.Errorf("failed to update token: %w", err)
+	rest.
+		SetBaseURL(options.httpBasicEndpoint).
+		SetHeader("Content-Type", "application/json").
+		SetHeader("Accept", "application/json").
+		SetTimeout(options.timeout)
+	if options.userAgent != "" {
+		rest.SetHeader("User-Agent", options.userAgent)
 	}
-	if ttl < 10*time.Minute {
-		return fmt.Errorf("token TTL is too short: %s", fmt.Sprint(ttl))
+	if cfg := options.tls.config(); cfg != nil {
+		rest.SetTLSClientConfig(cfg)
 	}
-	for {
+	if options.proxy != "" {
+		rest.SetProxy(options.proxy)
+	}
+
+	return rest, nil
+}
+
+// Close stops the token refresher and waits for it to exit
+// Revokes the access token or logs out of the admin session if WithRevokeTokenOnClose is set
+// Any method called after Close returns ErrClientClosed. Close is idempotent
+// If Close fails, e.g. ctx is done before the refresher exits, calling it again finishes the shutdown
+func (c *Client) Close(ctx context.Context) error {
+	c.closeMu.Lock()
+	defer c.closeMu.Unlock()
+
+	if c.closeDone {
+		return nil
+	}
+	c.closed.Store(true)
+
+	err := c.shutdown(ctx)
+	if err != nil {
+		return err
+	}
+	c.closeDone = true
+
+	return nil
+}
+
+// shutdown stops the token refresher and revokes the credentials. Safe to repeat until it succeeds
+func (c *Client) shutdown(ctx context.Context) error {
+	if c.stop != nil {
+		c.stop()
 		select {
+		case <-c.stopped:
 		case <-ctx.Done():
+			return fmt.Errorf("failed to wait for the token refresher: %w", ctx.Err())
+		}
+	}
+
+	if c.revokeOnClose && c.authMode == authModeSession {
+		return c.logout(ctx)
+	}
+
+	if c.revokeOnClose {
+		token := c.accessToken()
+		if token == "" {
 			return nil
-		case <-time.After(ttl - 10*time.Minute):
-			ttl, err = c.authenticate(ctx, clientID, clientSecret)
-			if err != nil {
-				return fmt.Errorf("failed to update token: %w", err)
-			}
 		}
+		_, err := c.doRequestWithToken(ctx, postRequest("/api/auth.token.revoke", map[string]string{
+			"accessToken": token,
+		}), token)
+		if err != nil {
+			return fmt.Errorf("failed to revoke token: %w", err)
+		}
+		c.forgetStoredToken(ctx, token)
+		c.log.Info("Revoked Peplink API token")
 	}
+
+	return nil
 }
 
+// authenticate reuses the token from the TokenStore if there is a valid one. Otherwise grants a new token
 func (c *Client) authenticate(ctx context.Context, clientID, clientSecret string) (time.Duration, error) {
+	if ttl, ok := c.loadStoredToken(ctx); ok {
+		return ttl, nil
+	}
+
+	err := c.throttle.acquireAuth(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to authenticate: %w", err)
+	}
+
 	type tokenRequest struct {
 		ClientID     string `json:"clientId"`
 		ClientSecret string `json:"clientSecret"`
@@ -91,6 +274,8 @@ func (c *Client) authenticate(ctx context.Context, clientID, clientSecret string
 	}
 	type tokenResponse struct {
 		Stat     string `json:"stat"`
+		Code     int    `json:"code,omitempty"`
+		Message  string `json:"message,omitempty"`
 		Response struct {
 			// The access token string for API call
 			AccessToken string `json:"accessToken"`
@@ -106,6 +291,7 @@ func (c *Client) authenticate(ctx context.Context, clientID, clientSecret string
 		Scope:        "api",
 	}).
 		SetResult(resp).
+		SetError(resp).
 		SetContext(ctx).
 		Post("/api/auth.token.grant")
 
@@ -114,7 +300,11 @@ func (c *Client) authenticate(ctx context.Context, clientID, clientSecret string
 	}
 
 	if resp.Stat != "ok" {
-		return 0, fmt.Errorf("failed to authenticate: stat='%s' body='%s'", resp.Stat, rr.Body())
+		return 0, fmt.Errorf("failed to authenticate: %w", newAPIError("/api/auth.token.grant", rr, &apiEnvelope{
+			Stat:    resp.Stat,
+			Code:    resp.Code,
+			Message: resp.Message,
+		}))
 	}
 
 	ttlInt, err := strconv.Atoi(resp.Response.ExpiresIn)
@@ -126,7 +316,66 @@ func (c *Client) authenticate(ctx context.Context, clientID, clientSecret string
 
 	c.log.Info("Authenticated against Peplink API", "status", rr.Status(), "TTL", fmt.Sprint(ttl))
 
-	c.httpClient.SetPathParam("accessToken", resp.Response.AccessToken)
+	c.setToken(resp.Response.AccessToken)
+	c.saveStoredToken(ctx, Token{
+		AccessToken: resp.Response.AccessToken,
+		ExpiresAt:   time.Now().Add(ttl),
+	})
 
 	return ttl, nil
 }
+
+func (c *Client) setToken(token string) {
+	c.tokenMu.Lock()
+	defer c.tokenMu.Unlock()
+
+	c.token = token
+	c.refreshStatus.LastRefresh = time.Now()
+}
+
+func (c *Client) accessToken() string {
+	c.tokenMu.Lock()
+	defer c.tokenMu.Unlock()
+
+	return c.token
+}
+
+// reauthenticate grants a new token after the API rejected the given one
+// Concurrent callers share a single grant request. Does nothing if the token was already replaced
+// The grant runs under the ctx of the first caller. If that ctx ends, the callers still waiting take over
+func (c *Client) reauthenticate(ctx context.Context, rejected string) error {
+	for {
+		c.tokenMu.Lock()
+		if c.token != rejected {
+			c.tokenMu.Unlock()
+			return nil
+		}
+		if r := c.refreshing; r != nil {
+			c.tokenMu.Unlock()
+			select {
+			case <-r.done:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+			if r.abandoned && ctx.Err() == nil {
+				continue
+			}
+			return r.err
+		}
+		r := &tokenRefresh{done: make(chan struct{})}
+		c.refreshing = r
+		c.tokenMu.Unlock()
+
+		c.log.Info("Access token rejected, re-authenticating")
+		c.forgetStoredToken(ctx, rejected)
+		r.err = c.renewAuth(ctx)
+		r.abandoned = r.err != nil && ctx.Err() != nil
+
+		c.tokenMu.Lock()
+		c.refreshing = nil
+		c.tokenMu.Unlock()
+		close(r.done)
+
+		return r.err
+	}
+}
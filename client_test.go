@@ -2,9 +2,13 @@ package peplink
 
 import (
 	"context"
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 )
@@ -57,8 +61,198 @@ func TestClient_authenticate(t *testing.T) {
 			require.Equal(t, tt.wantErr, err != nil, "authenticate() error = %v, wantErr %v", err, tt.wantErr)
 
 			if tt.wantToken {
-				require.NotEmpty(t, c.httpClient.PathParams["accessToken"], "authenticate() token is empty")
+				require.NotEmpty(t, c.accessToken(), "authenticate() token is empty")
 			}
 		})
 	}
 }
+
+func TestClient_reauthenticate(t *testing.T) {
+	var grants atomic.Int32
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/api/auth.token.grant":
+			grants.Add(1)
+			// Let concurrent callers pile up behind the refresh
+			time.Sleep(50 * time.Millisecond)
+			w.Write([]byte(`{"stat": "ok", "response": {"accessToken": "fresh", "expiresIn": "172800"}}`))
+		case "/api/info.frw.version":
+			if r.URL.Query().Get("accessToken") != "fresh" {
+				w.WriteHeader(http.StatusUnauthorized)
+				w.Write([]byte(`{"stat": "fail", "code": 401, "message": "Unauthorized"}`))
+				return
+			}
+			w.Write([]byte(`{"stat": "ok", "response": {"1": {"version": "8.3.0 build 5229", "bootable": true, "inUse": true}, "order": [1]}}`))
+		default:
+			t.Errorf("unexpected path: %s", r.URL.Path)
+		}
+	})
+	c.clientID = "client_id"
+	c.clientSecret = "client_secret"
+	c.token = "stale"
+
+	// require must not be called off the test goroutine, so the results are checked after Wait
+	versions := make([]string, 10)
+	errs := make([]error, 10)
+	var wg sync.WaitGroup
+	for i := range versions {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			versions[i], errs[i] = c.FirmwareVersion(context.Background())
+		}(i)
+	}
+	wg.Wait()
+
+	for i := range versions {
+		require.NoError(t, errs[i])
+		require.Equal(t, "8.3.0 build 5229", versions[i])
+	}
+
+	require.Equal(t, int32(1), grants.Load(), "concurrent callers must share a single token grant")
+	require.Equal(t, "fresh", c.accessToken())
+}
+
+func TestClient_reauthenticateAbandoned(t *testing.T) {
+	var grants atomic.Int32
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/api/auth.token.grant":
+			if grants.Add(1) == 1 {
+				// Hold the first grant until its caller gives up. The body is drained so the server sees the disconnect
+				io.Copy(io.Discard, r.Body)
+				<-r.Context().Done()
+				return
+			}
+			w.Write([]byte(`{"stat": "ok", "response": {"accessToken": "fresh", "expiresIn": "172800"}}`))
+		case "/api/info.frw.version":
+			if r.URL.Query().Get("accessToken") != "fresh" {
+				w.WriteHeader(http.StatusUnauthorized)
+				w.Write([]byte(`{"stat": "fail", "code": 401, "message": "Unauthorized"}`))
+				return
+			}
+			w.Write([]byte(`{"stat": "ok", "response": {"1": {"version": "8.3.0 build 5229", "bootable": true, "inUse": true}, "order": [1]}}`))
+		default:
+			t.Errorf("unexpected path: %s", r.URL.Path)
+		}
+	})
+	c.clientID = "client_id"
+	c.clientSecret = "client_secret"
+	c.token = "stale"
+
+	ctx, cancel := context.WithCancel(context.Background())
+	leaderErr := make(chan error, 1)
+	go func() {
+		_, err := c.FirmwareVersion(ctx)
+		leaderErr <- err
+	}()
+	require.Eventually(t, func() bool { return grants.Load() == 1 }, time.Second, time.Millisecond)
+
+	type result struct {
+		version string
+		err     error
+	}
+	follower := make(chan result, 1)
+	go func() {
+		version, err := c.FirmwareVersion(context.Background())
+		follower <- result{version, err}
+	}()
+	time.Sleep(20 * time.Millisecond)
+	cancel()
+
+	require.ErrorContains(t, <-leaderErr, context.Canceled.Error())
+	got := <-follower
+	require.NoError(t, got.err, "waiter must not fail with the ctx error of the caller which started the grant")
+	require.Equal(t, "8.3.0 build 5229", got.version)
+	require.Equal(t, int32(2), grants.Load())
+	require.Equal(t, "fresh", c.accessToken())
+}
+
+func TestClient_reauthenticateFailed(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusUnauthorized)
+		w.Write([]byte(`{"stat": "fail", "code": 401, "message": "Unauthorized"}`))
+	})
+	c.clientID = "client_id"
+	c.clientSecret = "wrong"
+	c.token = "stale"
+
+	_, err := c.FirmwareVersion(context.Background())
+	require.ErrorIs(t, err, ErrUnauthorized)
+	require.Equal(t, "stale", c.accessToken())
+}
+
+func TestClient_Close(t *testing.T) {
+	var revoked atomic.Int32
+	srv := httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			switch r.URL.Path {
+			case "/api/auth.token.grant":
+				w.Write([]byte(`{"stat": "ok", "response": {"accessToken": "token", "expiresIn": "172800"}}`))
+			case "/api/auth.token.revoke":
+				require.Equal(t, http.MethodPost, r.Method)
+				require.Equal(t, "token", r.URL.Query().Get("accessToken"))
+				revoked.Add(1)
+				w.Write([]byte(`{"stat": "ok"}`))
+			default:
+				t.Errorf("unexpected path: %s", r.URL.Path)
+			}
+		}),
+	)
+	defer srv.Close()
+
+	c, err := NewClient(context.Background(),
+		WithHTTPBasicClientID("client_id"),
+		WithHTTPBasicClientSecret("client_secret"),
+		WithHTTPBasicURL(srv.URL),
+		WithRevokeTokenOnClose(),
+	)
+	require.NoError(t, err)
+	require.Eventually(t, func() bool { return c.TokenRefreshStatus().Running }, time.Second, 5*time.Millisecond)
+
+	require.NoError(t, c.Close(context.Background()))
+	require.False(t, c.TokenRefreshStatus().Running, "refresher must exit before Close returns")
+	require.Equal(t, int32(1), revoked.Load())
+
+	_, err = c.FirmwareVersion(context.Background())
+	require.ErrorIs(t, err, ErrClientClosed)
+	_, err = c.SerialNumberSNMP(context.Background())
+	require.ErrorIs(t, err, ErrClientClosed)
+
+	require.NoError(t, c.Close(context.Background()), "Close must be idempotent")
+	require.Equal(t, int32(1), revoked.Load())
+}
+
+func TestClient_CloseRetry(t *testing.T) {
+	var revoked atomic.Int32
+	stopped := make(chan struct{})
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/api/auth.token.revoke", r.URL.Path)
+		revoked.Add(1)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"stat": "ok"}`))
+	})
+	c.token = "token"
+	c.stop = func() {}
+	c.stopped = stopped
+	c.revokeOnClose = true
+
+	// The refresher doesn't exit in time
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	require.ErrorIs(t, c.Close(ctx), context.Canceled)
+	require.Equal(t, int32(0), revoked.Load())
+	_, err := c.FirmwareVersion(context.Background())
+	require.ErrorIs(t, err, ErrClientClosed, "client is closed even if Close failed")
+
+	close(stopped)
+	require.NoError(t, c.Close(context.Background()))
+	require.Equal(t, int32(1), revoked.Load(), "retried Close must revoke the token")
+
+	require.NoError(t, c.Close(context.Background()))
+	require.Equal(t, int32(1), revoked.Load())
+}
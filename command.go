@@ -0,0 +1,39 @@
+package peplink
+
+import "fmt"
+
+// CommandOption is the option of the command which changes the device
+type CommandOption func(*commandOptions)
+
+type commandOptions struct {
+	confirm bool
+	dryRun  bool
+}
+
+// WithConfirm confirms the command. Commands which change the device refuse to run without it
+func WithConfirm() CommandOption {
+	return func(o *commandOptions) {
+		o.confirm = true
+	}
+}
+
+// WithDryRun checks the command and returns what it would do without changing the device
+// Doesn't need WithConfirm
+func WithDryRun() CommandOption {
+	return func(o *commandOptions) {
+		o.dryRun = true
+	}
+}
+
+// newCommandOptions applies the options and checks the confirmation
+func newCommandOptions(command string, opts []CommandOption) (commandOptions, error) {
+	o := commandOptions{}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if !o.confirm && !o.dryRun {
+		return o, fmt.Errorf("%w: %s changes the device, use WithConfirm or WithDryRun", ErrNotConfirmed, command)
+	}
+
+	return o, nil
+}
@@ -0,0 +1,32 @@
+package peplink
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewCommandOptions(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    []CommandOption
+		want    commandOptions
+		wantErr error
+	}{
+		{"not confirmed", nil, commandOptions{}, ErrNotConfirmed},
+		{"confirmed", []CommandOption{WithConfirm()}, commandOptions{confirm: true}, nil},
+		{"dry run", []CommandOption{WithDryRun()}, commandOptions{dryRun: true}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := newCommandOptions("reboot", tt.opts)
+			if tt.wantErr != nil {
+				require.ErrorIs(t, err, tt.wantErr)
+				require.ErrorContains(t, err, "reboot changes the device")
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,27 @@
+package peplink
+
+import (
+	"context"
+	"fmt"
+)
+
+// DeviceInfo identifies the device
+type DeviceInfo struct {
+	SerialNumber     string   `json:"serialNumber"`     // Serial number, e.g. 1111-2222-3333
+	ProductCode      string   `json:"productCode"`      // Product code. Not available via SNMP
+	Model            string   `json:"model"`            // Model name
+	Hostname         string   `json:"hostname"`         // Device name
+	HardwareRevision string   `json:"hardwareRevision"` // Hardware revision. Not available via SNMP
+	Uptime           int      `json:"uptime"`           // System uptime in seconds
+	MACAddresses     []string `json:"macAddress"`       // MAC addresses of the ports
+}
+
+// DeviceInfo returns the identity of the device
+func (c *Client) DeviceInfo(ctx context.Context) (DeviceInfo, error) {
+	info, err := doRequestInto[DeviceInfo](ctx, c, getRequest("/api/status.system.info"))
+	if err != nil {
+		return DeviceInfo{}, fmt.Errorf("failed to get device info via http: %w", err)
+	}
+
+	return info, nil
+}
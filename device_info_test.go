@@ -0,0 +1,59 @@
+package peplink
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_DeviceInfo(t *testing.T) {
+	tests := []struct {
+		name     string
+		response string
+		want     DeviceInfo
+		wantErr  bool
+	}{
+		{"happy",
+			`{"stat": "ok", "response": {
+				"serialNumber": "1111-2222-3333",
+				"productCode": "MAX-BR1-MINI-LTEA-W-T",
+				"model": "MAX BR1 Mini",
+				"hostname": "site-a",
+				"hardwareRevision": "2",
+				"uptime": 3314261,
+				"macAddress": ["00:1A:DD:00:00:01", "00:1A:DD:00:00:02"]
+			}}`,
+			DeviceInfo{
+				SerialNumber:     "1111-2222-3333",
+				ProductCode:      "MAX-BR1-MINI-LTEA-W-T",
+				Model:            "MAX BR1 Mini",
+				Hostname:         "site-a",
+				HardwareRevision: "2",
+				Uptime:           3314261,
+				MACAddresses:     []string{"00:1A:DD:00:00:01", "00:1A:DD:00:00:02"},
+			},
+			false,
+		},
+		{"not supported",
+			`{"stat": "fail", "code": 404, "message": "Not Found"}`,
+			DeviceInfo{},
+			true,
+		},
+	}
+	strict(t)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+				require.Equal(t, "/api/status.system.info", r.URL.Path)
+				w.Header().Set("Content-Type", "application/json")
+				w.Write([]byte(tt.response))
+			})
+
+			got, err := c.DeviceInfo(context.Background())
+			require.Equal(t, tt.wantErr, err != nil, "DeviceInfo() error = %v, wantErr %v", err, tt.wantErr)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,67 @@
+package peplink
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrUnauthorized is matched when the API rejects the access token or credentials
+	ErrUnauthorized = errors.New("unauthorized")
+	// ErrTokenExpired is matched when the API reports that the access token is expired
+	ErrTokenExpired = errors.New("token expired")
+	// ErrNotSupported is matched when the endpoint or the operation isn't supported by the device
+	ErrNotSupported = errors.New("not supported")
+	// ErrRateLimited is matched when the device throttles the requests
+	ErrRateLimited = errors.New("rate limited")
+	// ErrClientClosed is returned by the methods of the Client after Close
+	ErrClientClosed = errors.New("client is closed")
+	// ErrInvalidOption is matched by the errors of NewClient caused by the options
+	ErrInvalidOption = errors.New("invalid option")
+	// ErrNotConfirmed is returned by the commands which change the device if they are called without WithConfirm
+	ErrNotConfirmed = errors.New("not confirmed")
+)
+
+// Peplink API error codes
+const (
+	codeUnauthorized = 401
+	codeNotFound     = 404
+	codeRateLimited  = 429
+	codeNotSupported = 501
+)
+
+// APIError is returned when the Peplink API answers with a status other than 'ok'
+// Use errors.Is with the Err* sentinels to classify it
+type APIError struct {
+	StatusCode int           // HTTP status code
+	Code       int           // Peplink error code. 0 if the response doesn't contain one
+	Message    string        // Peplink error message or the raw body if the response isn't an API envelope
+	Endpoint   string        // API endpoint of the request
+	Notice     interface{}   // Extra information about the API request
+	RetryAfter time.Duration // Delay requested by the Retry-After header. 0 if it is absent
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("peplink api error: endpoint='%s' status=%d code=%d message='%s'", e.Endpoint, e.StatusCode, e.Code, e.Message)
+}
+
+// Is reports whether the error belongs to one of the Err* sentinels
+func (e *APIError) Is(target error) bool {
+	switch target {
+	case ErrUnauthorized:
+		return e.Code == codeUnauthorized || e.StatusCode == http.StatusUnauthorized
+	case ErrTokenExpired:
+		return (e.Code == codeUnauthorized || e.StatusCode == http.StatusUnauthorized) &&
+			strings.Contains(strings.ToLower(e.Message), "expired")
+	case ErrNotSupported:
+		return e.Code == codeNotFound || e.Code == codeNotSupported ||
+			e.StatusCode == http.StatusNotFound || e.StatusCode == http.StatusNotImplemented
+	case ErrRateLimited:
+		return e.Code == codeRateLimited || e.StatusCode == http.StatusTooManyRequests
+	}
+
+	return false
+}
@@ -0,0 +1,74 @@
+package peplink
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_doRequestAPIError(t *testing.T) {
+	tests := []struct {
+		name       string
+		statusCode int
+		response   string
+		want       *APIError
+		is         []error
+		isNot      []error
+	}{
+		{"unauthorized",
+			http.StatusOK,
+			`{"stat": "fail", "code": 401, "message": "Unauthorized"}`,
+			&APIError{StatusCode: http.StatusOK, Code: 401, Message: "Unauthorized", Endpoint: "/api/info.frw.version"},
+			[]error{ErrUnauthorized},
+			[]error{ErrTokenExpired, ErrNotSupported, ErrRateLimited},
+		},
+		{"token expired",
+			http.StatusUnauthorized,
+			`{"stat": "fail", "code": 401, "message": "Access token expired"}`,
+			&APIError{StatusCode: http.StatusUnauthorized, Code: 401, Message: "Access token expired", Endpoint: "/api/info.frw.version"},
+			[]error{ErrUnauthorized, ErrTokenExpired},
+			[]error{ErrNotSupported, ErrRateLimited},
+		},
+		{"not supported",
+			http.StatusNotFound,
+			`{"stat": "fail", "code": 404, "message": "Not Found", "notice": {"detail": "unknown endpoint"}}`,
+			&APIError{StatusCode: http.StatusNotFound, Code: 404, Message: "Not Found", Endpoint: "/api/info.frw.version",
+				Notice: map[string]interface{}{"detail": "unknown endpoint"}},
+			[]error{ErrNotSupported},
+			[]error{ErrUnauthorized, ErrRateLimited},
+		},
+		{"rate limited without envelope",
+			http.StatusTooManyRequests,
+			`Too Many Requests`,
+			&APIError{StatusCode: http.StatusTooManyRequests, Message: "Too Many Requests", Endpoint: "/api/info.frw.version"},
+			[]error{ErrRateLimited},
+			[]error{ErrUnauthorized, ErrNotSupported},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(tt.statusCode)
+				w.Write([]byte(tt.response))
+			})
+
+			_, err := c.doRequest(context.Background(), getRequest("/api/info.frw.version"))
+			require.Error(t, err)
+
+			apiErr := &APIError{}
+			require.True(t, errors.As(err, &apiErr))
+			require.Equal(t, tt.want, apiErr)
+
+			for _, target := range tt.is {
+				require.ErrorIs(t, err, target)
+			}
+			for _, target := range tt.isNot {
+				require.NotErrorIs(t, err, target)
+			}
+		})
+	}
+}
@@ -2,11 +2,9 @@ package peplink
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
-	"net/http"
-
-	"github.com/jmespath/go-jmespath"
+	"regexp"
+	"strconv"
 )
 
 type firmware struct {
@@ -17,45 +15,146 @@ type firmware struct {
 
 // Returns the firmware version of the device
 func (c *Client) FirmwareVersion(ctx context.Context) (string, error) {
-	msg, err := c.doRequest(ctx, "/api/info.frw.version", http.MethodGet, nil)
+	firmwares, err := doRequestInto[OrderedMap[firmware]](ctx, c, getRequest("/api/info.frw.version"))
 	if err != nil {
 		return "", fmt.Errorf("failed to get firmware version via http: %w", err)
 	}
-	var buf interface{}
 
-	err = json.Unmarshal(msg, &buf)
-	if err != nil {
-		return "", fmt.Errorf("failed to unmarshal json: %w", err)
+	for _, f := range firmwares {
+		if f.Value.InUse {
+			return f.Value.Version, nil
+		}
 	}
 
-	orderI, err := jmespath.Search("order", buf)
-	if err != nil {
-		return "", fmt.Errorf("failed to get firmware version from json: %w", err)
-	}
-	for _, oi := range orderI.([]interface{}) {
-		i, ok := oi.(float64)
-		if !ok {
-			return "", fmt.Errorf("failed to get firmware version: order is not a float64")
-		}
-		versionI, err := jmespath.Search(fmt.Sprintf("\"%d\"", int(i)), buf)
-		if err != nil {
-			return "", fmt.Errorf("failed to get firmware version from json: %w", err)
+	return "", fmt.Errorf("failed to get firmware version: no firmware in use")
+}
+
+// FirmwareSlot is the firmware installed in the slot of the device
+type FirmwareSlot struct {
+	ID         int     // Slot number
+	Raw        string  // Firmware version as reported by the device
+	Version    Version // Parsed firmware version. Zero if VersionErr is set
+	VersionErr error   // Error of parsing Raw. The slot is still listed
+	Bootable   bool    // Device can boot from the slot
+	InUse      bool    // Device is running the firmware of the slot
+}
+
+// FirmwareSlots is the firmware inventory of the device in the slot order
+type FirmwareSlots []FirmwareSlot
+
+// InUse returns the slot the device is running
+func (s FirmwareSlots) InUse() (FirmwareSlot, bool) {
+	for _, slot := range s {
+		if slot.InUse {
+			return slot, true
 		}
-		// Do marshal/unmarshal to workaround interface{} hussle
-		buf, err := json.Marshal(versionI)
-		if err != nil {
-			return "", fmt.Errorf("failed to get firmware version from json: %w", err)
+	}
+
+	return FirmwareSlot{}, false
+}
+
+// Get returns the slot by ID
+func (s FirmwareSlots) Get(id int) (FirmwareSlot, bool) {
+	for _, slot := range s {
+		if slot.ID == id {
+			return slot, true
 		}
-		version := firmware{}
-		err = json.Unmarshal(buf, &version)
-		if err != nil {
-			return "", fmt.Errorf("failed to get firmware version from json: %w", err)
+	}
+
+	return FirmwareSlot{}, false
+}
+
+// Fallback returns the first slot the device is not running
+func (s FirmwareSlots) Fallback() (FirmwareSlot, bool) {
+	for _, slot := range s {
+		if !slot.InUse {
+			return slot, true
 		}
-		if version.InUse {
-			return version.Version, nil
+	}
+
+	return FirmwareSlot{}, false
+}
+
+// Firmwares returns the firmware of every slot of the device
+// Slots with the unparsable version are returned with VersionErr set
+func (c *Client) Firmwares(ctx context.Context) (FirmwareSlots, error) {
+	firmwares, err := doRequestInto[OrderedMap[firmware]](ctx, c, getRequest("/api/info.frw.version"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get firmwares via http: %w", err)
+	}
+
+	slots := make(FirmwareSlots, 0, len(firmwares))
+	for _, f := range firmwares {
+		slot := FirmwareSlot{ID: f.ID, Raw: f.Value.Version, Bootable: f.Value.Bootable, InUse: f.Value.InUse}
+		slot.Version, slot.VersionErr = ParseVersion(f.Value.Version)
+		slots = append(slots, slot)
+	}
+
+	return slots, nil
+}
+
+// Version is the parsed firmware version like "8.2.0s036 build 4979"
+type Version struct {
+	Major  int
+	Minor  int
+	Patch  int
+	Suffix string // Special build suffix like "s036". Empty for GA releases
+	Build  int    // Build number. 0 if the version has no build
+}
+
+var versionRe = regexp.MustCompile(`^(\d+)\.(\d+)\.(\d+)([0-9A-Za-z]*)(?:\s+build\s+(\d+))?$`)
+
+// ParseVersion parses the firmware version reported by the device
+func ParseVersion(s string) (Version, error) {
+	m := versionRe.FindStringSubmatch(s)
+	if m == nil {
+		return Version{}, fmt.Errorf("invalid firmware version %q", s)
+	}
+
+	v := Version{Suffix: m[4]}
+	v.Major, _ = strconv.Atoi(m[1])
+	v.Minor, _ = strconv.Atoi(m[2])
+	v.Patch, _ = strconv.Atoi(m[3])
+	if m[5] != "" {
+		v.Build, _ = strconv.Atoi(m[5])
+	}
+
+	return v, nil
+}
+
+// String returns the version in the format of the device
+func (v Version) String() string {
+	s := fmt.Sprintf("%d.%d.%d%s", v.Major, v.Minor, v.Patch, v.Suffix)
+	if v.Build > 0 {
+		s += fmt.Sprintf(" build %d", v.Build)
+	}
+
+	return s
+}
+
+// Compare returns -1, 0 or +1 if v is older, the same or newer than o
+// Versions are compared by major, minor, patch and then build. The suffix is compared last
+func (v Version) Compare(o Version) int {
+	for _, d := range []int{v.Major - o.Major, v.Minor - o.Minor, v.Patch - o.Patch, v.Build - o.Build} {
+		switch {
+		case d < 0:
+			return -1
+		case d > 0:
+			return 1
 		}
 	}
 
-	return "", fmt.Errorf("failed to get firmware version: no firmware in use")
+	switch {
+	case v.Suffix < o.Suffix:
+		return -1
+	case v.Suffix > o.Suffix:
+		return 1
+	}
+
+	return 0
+}
 
+// Before reports whether v is older than major.minor.patch regardless of suffix and build
+func (v Version) Before(major, minor, patch int) bool {
+	return v.Compare(Version{Major: major, Minor: minor, Patch: patch}) < 0
 }
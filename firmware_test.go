@@ -2,6 +2,7 @@ package peplink
 
 import (
 	"context"
+	"fmt"
 	"log/slog"
 	"net/http"
 	"net/http/httptest"
@@ -43,6 +44,7 @@ func TestClient_FirmwareVersion(t *testing.T) {
 		},
 		// TODO: Add test cases.
 	}
+	strict(t)
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			srv := httptest.NewServer(
@@ -70,3 +72,130 @@ func TestClient_FirmwareVersion(t *testing.T) {
 		})
 	}
 }
+
+func TestClient_Firmwares(t *testing.T) {
+	tests := []struct {
+		name     string
+		response string
+		want     FirmwareSlots
+		wantErr  bool
+	}{
+		{"happy",
+			`{"stat": "ok", "response": {
+				"1": {"version": "8.2.0s036 build 4979", "bootable": false, "inUse": false},
+				"2": {"version": "8.3.0 build 5229", "bootable": true, "inUse": true},
+				"order": [1, 2]
+			}}`,
+			FirmwareSlots{
+				{ID: 1, Raw: "8.2.0s036 build 4979", Version: Version{Major: 8, Minor: 2, Patch: 0, Suffix: "s036", Build: 4979}},
+				{ID: 2, Raw: "8.3.0 build 5229", Version: Version{Major: 8, Minor: 3, Patch: 0, Build: 5229}, Bootable: true, InUse: true},
+			},
+			false,
+		},
+		{"unparsable version",
+			`{"stat": "ok", "response": {
+				"1": {"version": "unknown", "bootable": true, "inUse": false},
+				"2": {"version": "8.3.0 build 5229", "bootable": true, "inUse": true},
+				"order": [1, 2]
+			}}`,
+			FirmwareSlots{
+				{ID: 1, Raw: "unknown", VersionErr: fmt.Errorf("invalid firmware version %q", "unknown"), Bootable: true},
+				{ID: 2, Raw: "8.3.0 build 5229", Version: Version{Major: 8, Minor: 3, Patch: 0, Build: 5229}, Bootable: true, InUse: true},
+			},
+			false,
+		},
+	}
+	strict(t)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+				require.Equal(t, "/api/info.frw.version", r.URL.Path)
+				w.Header().Set("Content-Type", "application/json")
+				w.Write([]byte(tt.response))
+			})
+
+			got, err := c.Firmwares(context.Background())
+			require.Equal(t, tt.wantErr, err != nil, "Firmwares() error = %v, wantErr %v", err, tt.wantErr)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestFirmwareSlots(t *testing.T) {
+	slots := FirmwareSlots{
+		{ID: 1, Version: Version{Major: 8, Minor: 2}},
+		{ID: 2, Version: Version{Major: 8, Minor: 3}, Bootable: true, InUse: true},
+	}
+
+	inUse, ok := slots.InUse()
+	require.True(t, ok)
+	require.Equal(t, 2, inUse.ID)
+
+	fallback, ok := slots.Fallback()
+	require.True(t, ok)
+	require.Equal(t, 1, fallback.ID)
+	require.False(t, fallback.Bootable)
+
+	_, ok = FirmwareSlots{}.InUse()
+	require.False(t, ok)
+
+	slot, ok := slots.Get(2)
+	require.True(t, ok)
+	require.True(t, slot.InUse)
+
+	_, ok = slots.Get(3)
+	require.False(t, ok)
+}
+
+func TestParseVersion(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    Version
+		wantErr bool
+	}{
+		{"8.3.0 build 5229", Version{Major: 8, Minor: 3, Patch: 0, Build: 5229}, false},
+		{"8.2.0s036 build 4979", Version{Major: 8, Minor: 2, Patch: 0, Suffix: "s036", Build: 4979}, false},
+		{"7.1.2", Version{Major: 7, Minor: 1, Patch: 2}, false},
+		{"8.3 build 1", Version{}, true},
+		{"", Version{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := ParseVersion(tt.in)
+			require.Equal(t, tt.wantErr, err != nil, "ParseVersion() error = %v, wantErr %v", err, tt.wantErr)
+			require.Equal(t, tt.want, got)
+			if !tt.wantErr {
+				require.Equal(t, tt.in, got.String())
+			}
+		})
+	}
+}
+
+func TestVersion_Compare(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"8.3.0 build 5229", "8.3.0 build 5229", 0},
+		{"8.2.0s036 build 4979", "8.3.0 build 5229", -1},
+		{"8.3.1 build 10", "8.3.0 build 5229", 1},
+		{"9.0.0 build 1", "8.10.0 build 9999", 1},
+		{"8.3.0 build 5230", "8.3.0 build 5229", 1},
+		{"8.3.0s001 build 5229", "8.3.0 build 5229", 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.a+" vs "+tt.b, func(t *testing.T) {
+			a, err := ParseVersion(tt.a)
+			require.NoError(t, err)
+			b, err := ParseVersion(tt.b)
+			require.NoError(t, err)
+			require.Equal(t, tt.want, a.Compare(b))
+			require.Equal(t, -tt.want, b.Compare(a))
+		})
+	}
+
+	v, err := ParseVersion("8.2.0s036 build 4979")
+	require.NoError(t, err)
+	require.True(t, v.Before(8, 3, 0))
+	require.False(t, v.Before(8, 2, 0))
+}
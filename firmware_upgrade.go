@@ -0,0 +1,138 @@
+package peplink
+
+import (
+	"context"
+	"fmt"
+)
+
+// FirmwareUpgradeState is the state of the firmware upgrade
+type FirmwareUpgradeState string
+
+const (
+	FirmwareUpgradeIdle        FirmwareUpgradeState = "idle"        // No upgrade was started
+	FirmwareUpgradeDownloading FirmwareUpgradeState = "downloading" // Device downloads or receives the image
+	FirmwareUpgradeWriting     FirmwareUpgradeState = "writing"     // Image is written to the slot
+	FirmwareUpgradeDone        FirmwareUpgradeState = "done"        // Image is in the slot and boots after the reboot
+	FirmwareUpgradeFailed      FirmwareUpgradeState = "failed"      // See the message for the reason
+	FirmwareUpgradePlanned     FirmwareUpgradeState = "planned"     // Dry run. The upgrade would be started
+)
+
+// FirmwareUpgrade is the state of the firmware upgrade
+type FirmwareUpgrade struct {
+	State    FirmwareUpgradeState `json:"state"`
+	Progress int                  `json:"progress"` // Progress in percent [0,100]
+	Slot     int                  `json:"slot"`     // Slot the image is written to
+	Version  string               `json:"version"`  // Version of the image. Empty until the image is verified
+	Message  string               `json:"message"`  // Reason of the failure
+}
+
+// InProgress reports whether the upgrade is running
+func (u FirmwareUpgrade) InProgress() bool {
+	return u.State == FirmwareUpgradeDownloading || u.State == FirmwareUpgradeWriting
+}
+
+// firmwareUpgradeRequest is the body of /api/cmd.frw.upgrade
+type firmwareUpgradeRequest struct {
+	URL string `json:"url,omitempty"` // Image to download. The latest firmware from the Peplink server if empty
+}
+
+// UpgradeFirmware makes the device download the image from the URL and write it to the slot which is not in use
+// The latest firmware from the Peplink server is used if the URL is empty
+// Requires WithConfirm. WithDryRun returns the planned upgrade without starting it
+func (c *Client) UpgradeFirmware(ctx context.Context, url string, opts ...CommandOption) (FirmwareUpgrade, error) {
+	return c.upgradeFirmware(ctx, postRequest("/api/cmd.frw.upgrade", firmwareUpgradeRequest{URL: url}), opts)
+}
+
+// UploadFirmware uploads the image to the device and writes it to the slot which is not in use
+// Requires WithConfirm. WithDryRun returns the planned upgrade without uploading the image
+func (c *Client) UploadFirmware(ctx context.Context, name string, image []byte, opts ...CommandOption) (FirmwareUpgrade, error) {
+	if len(image) == 0 {
+		return FirmwareUpgrade{}, fmt.Errorf("failed to upload firmware: image is empty")
+	}
+
+	return c.upgradeFirmware(ctx, uploadRequest("/api/cmd.frw.upgrade", "firmware", name, image), opts)
+}
+
+func (c *Client) upgradeFirmware(ctx context.Context, req apiRequest, opts []CommandOption) (FirmwareUpgrade, error) {
+	o, err := newCommandOptions("firmware upgrade", opts)
+	if err != nil {
+		return FirmwareUpgrade{}, err
+	}
+
+	status, err := c.FirmwareUpgradeStatus(ctx)
+	if err != nil {
+		return FirmwareUpgrade{}, fmt.Errorf("failed to upgrade firmware: %w", err)
+	}
+	if status.InProgress() {
+		return status, fmt.Errorf("failed to upgrade firmware: upgrade is already %s", status.State)
+	}
+
+	slots, err := c.Firmwares(ctx)
+	if err != nil {
+		return FirmwareUpgrade{}, fmt.Errorf("failed to upgrade firmware: %w", err)
+	}
+	target, ok := slots.Fallback()
+	if !ok {
+		return FirmwareUpgrade{}, fmt.Errorf("failed to upgrade firmware: no slot other than the one in use")
+	}
+
+	if o.dryRun {
+		return FirmwareUpgrade{State: FirmwareUpgradePlanned, Slot: target.ID}, nil
+	}
+
+	c.log.Info("Starting Peplink firmware upgrade", "slot", target.ID)
+	upgrade, err := doRequestInto[FirmwareUpgrade](ctx, c, req)
+	if err != nil {
+		return FirmwareUpgrade{}, fmt.Errorf("failed to upgrade firmware: %w", err)
+	}
+
+	return upgrade, nil
+}
+
+// FirmwareUpgradeStatus returns the state of the last firmware upgrade
+func (c *Client) FirmwareUpgradeStatus(ctx context.Context) (FirmwareUpgrade, error) {
+	upgrade, err := doRequestInto[FirmwareUpgrade](ctx, c, getRequest("/api/status.frw.upgrade"))
+	if err != nil {
+		return FirmwareUpgrade{}, fmt.Errorf("failed to get firmware upgrade status: %w", err)
+	}
+
+	return upgrade, nil
+}
+
+// firmwareBootRequest is the body of /api/cmd.frw.boot
+type firmwareBootRequest struct {
+	Slot int `json:"slot"`
+}
+
+// SetBootFirmware selects the slot the device boots on the next reboot
+// The slot must be bootable. Requires WithConfirm. WithDryRun only checks the slot
+func (c *Client) SetBootFirmware(ctx context.Context, slot int, opts ...CommandOption) (FirmwareSlot, error) {
+	o, err := newCommandOptions("firmware slot switch", opts)
+	if err != nil {
+		return FirmwareSlot{}, err
+	}
+
+	slots, err := c.Firmwares(ctx)
+	if err != nil {
+		return FirmwareSlot{}, fmt.Errorf("failed to set boot firmware: %w", err)
+	}
+	target, ok := slots.Get(slot)
+	if !ok {
+		return FirmwareSlot{}, fmt.Errorf("failed to set boot firmware: no slot %d", slot)
+	}
+	if !target.Bootable {
+		return target, fmt.Errorf("failed to set boot firmware: slot %d with %s is not bootable", slot, target.Raw)
+	}
+
+	if o.dryRun {
+		return target, nil
+	}
+
+	c.log.Info("Switching Peplink boot firmware", "slot", slot, "version", target.Raw)
+	_, err = c.doRequest(ctx, postRequest("/api/cmd.frw.boot", firmwareBootRequest{Slot: slot}))
+	if err != nil {
+		return FirmwareSlot{}, fmt.Errorf("failed to set boot firmware: %w", err)
+	}
+
+	return target, nil
+}
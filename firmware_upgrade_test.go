@@ -0,0 +1,266 @@
+package peplink
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// firmwareDevice simulates the firmware slots of the device
+// Every status request moves the running upgrade one step forward
+type firmwareDevice struct {
+	mu       sync.Mutex
+	slots    []firmware
+	bootSlot int // slot which boots next. 0 is the slot in use
+	upgrade  FirmwareUpgrade
+	image    string // version of the image to write
+	commands int    // number of the commands which changed the device
+}
+
+func newFirmwareDevice(t *testing.T) (*firmwareDevice, *Client) {
+	t.Helper()
+
+	d := &firmwareDevice{
+		slots: []firmware{
+			{Version: "8.2.0s036 build 4979", Bootable: true},
+			{Version: "8.3.0 build 5229", Bootable: true, InUse: true},
+		},
+		upgrade: FirmwareUpgrade{State: FirmwareUpgradeIdle},
+	}
+
+	return d, newTestClient(t, d.serveHTTP)
+}
+
+func (d *firmwareDevice) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	fail := func(msg string) {
+		w.Write([]byte(fmt.Sprintf(`{"stat": "fail", "code": 400, "message": %q}`, msg)))
+	}
+	ok := func(response any) {
+		b, _ := json.Marshal(map[string]any{"stat": "ok", "response": response})
+		w.Write(b)
+	}
+
+	switch r.URL.Path {
+	case "/api/info.frw.version":
+		response := map[string]any{}
+		order := []int{}
+		for i, s := range d.slots {
+			response[fmt.Sprint(i+1)] = s
+			order = append(order, i+1)
+		}
+		response["order"] = order
+		ok(response)
+
+	case "/api/status.frw.upgrade":
+		d.advance()
+		ok(d.upgrade)
+
+	case "/api/cmd.frw.upgrade":
+		if d.upgrade.InProgress() {
+			fail("Upgrade in progress")
+			return
+		}
+		d.image = "8.3.1 build 5300"
+		if strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data") {
+			f, _, err := r.FormFile("firmware")
+			if err != nil {
+				fail("Missing firmware")
+				return
+			}
+			b, _ := io.ReadAll(f)
+			d.image = string(b)
+		} else {
+			req := firmwareUpgradeRequest{}
+			json.NewDecoder(r.Body).Decode(&req)
+			if req.URL == "" {
+				d.image = "8.4.0 build 6000"
+			}
+		}
+		d.commands++
+		for i, s := range d.slots {
+			if !s.InUse {
+				d.upgrade = FirmwareUpgrade{State: FirmwareUpgradeDownloading, Slot: i + 1}
+				break
+			}
+		}
+		ok(d.upgrade)
+
+	case "/api/cmd.frw.boot":
+		req := firmwareBootRequest{}
+		json.NewDecoder(r.Body).Decode(&req)
+		if req.Slot < 1 || req.Slot > len(d.slots) || !d.slots[req.Slot-1].Bootable {
+			fail("Invalid slot")
+			return
+		}
+		d.commands++
+		d.bootSlot = req.Slot
+		ok(nil)
+
+	default:
+		w.WriteHeader(http.StatusNotFound)
+		fail("Not Found")
+	}
+}
+
+// advance moves the upgrade downloading -> writing -> done
+func (d *firmwareDevice) advance() {
+	switch d.upgrade.State {
+	case FirmwareUpgradeDownloading:
+		d.upgrade.State = FirmwareUpgradeWriting
+		d.upgrade.Progress = 50
+		d.upgrade.Version = d.image
+		d.slots[d.upgrade.Slot-1] = firmware{Version: d.image}
+	case FirmwareUpgradeWriting:
+		d.upgrade.State = FirmwareUpgradeDone
+		d.upgrade.Progress = 100
+		d.slots[d.upgrade.Slot-1].Bootable = true
+		d.bootSlot = d.upgrade.Slot
+	}
+}
+
+func TestClient_UpgradeFirmware(t *testing.T) {
+	tests := []struct {
+		name        string
+		upgrade     func(c *Client) (FirmwareUpgrade, error)
+		want        FirmwareUpgrade
+		wantErr     error
+		wantImage   string
+		wantChanged bool
+	}{
+		{"not confirmed",
+			func(c *Client) (FirmwareUpgrade, error) {
+				return c.UpgradeFirmware(context.Background(), "http://example.com/fw.bin")
+			},
+			FirmwareUpgrade{},
+			ErrNotConfirmed,
+			"",
+			false,
+		},
+		{"dry run",
+			func(c *Client) (FirmwareUpgrade, error) {
+				return c.UpgradeFirmware(context.Background(), "http://example.com/fw.bin", WithDryRun())
+			},
+			FirmwareUpgrade{State: FirmwareUpgradePlanned, Slot: 1},
+			nil,
+			"",
+			false,
+		},
+		{"url",
+			func(c *Client) (FirmwareUpgrade, error) {
+				return c.UpgradeFirmware(context.Background(), "http://example.com/fw.bin", WithConfirm())
+			},
+			FirmwareUpgrade{State: FirmwareUpgradeDownloading, Slot: 1},
+			nil,
+			"8.3.1 build 5300",
+			true,
+		},
+		{"latest",
+			func(c *Client) (FirmwareUpgrade, error) {
+				return c.UpgradeFirmware(context.Background(), "", WithConfirm())
+			},
+			FirmwareUpgrade{State: FirmwareUpgradeDownloading, Slot: 1},
+			nil,
+			"8.4.0 build 6000",
+			true,
+		},
+		{"upload",
+			func(c *Client) (FirmwareUpgrade, error) {
+				return c.UploadFirmware(context.Background(), "fw.bin", []byte("8.3.0s001 build 5230"), WithConfirm())
+			},
+			FirmwareUpgrade{State: FirmwareUpgradeDownloading, Slot: 1},
+			nil,
+			"8.3.0s001 build 5230",
+			true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d, c := newFirmwareDevice(t)
+
+			got, err := tt.upgrade(c)
+			if tt.wantErr != nil {
+				require.ErrorIs(t, err, tt.wantErr)
+			} else {
+				require.NoError(t, err)
+			}
+			require.Equal(t, tt.want, got)
+			require.Equal(t, tt.wantChanged, d.commands > 0)
+			if !tt.wantChanged {
+				return
+			}
+
+			// The preflight status moves the upgrade to writing
+			status, err := c.UpgradeFirmware(context.Background(), "", WithConfirm())
+			require.ErrorContains(t, err, "upgrade is already writing")
+			require.Equal(t, FirmwareUpgrade{State: FirmwareUpgradeWriting, Progress: 50, Slot: 1, Version: tt.wantImage}, status)
+			require.True(t, status.InProgress())
+
+			status, err = c.FirmwareUpgradeStatus(context.Background())
+			require.NoError(t, err)
+			require.Equal(t, FirmwareUpgrade{State: FirmwareUpgradeDone, Progress: 100, Slot: 1, Version: tt.wantImage}, status)
+			require.False(t, status.InProgress())
+
+			slots, err := c.Firmwares(context.Background())
+			require.NoError(t, err)
+			require.Equal(t, tt.wantImage, slots[0].Version.String())
+			require.True(t, slots[0].Bootable)
+			require.Equal(t, 1, d.bootSlot)
+		})
+	}
+}
+
+func TestClient_SetBootFirmware(t *testing.T) {
+	tests := []struct {
+		name         string
+		slot         int
+		bootable     bool
+		opts         []CommandOption
+		wantErr      bool
+		wantBootSlot int
+	}{
+		{"not confirmed", 1, true, nil, true, 0},
+		{"dry run", 1, true, []CommandOption{WithDryRun()}, false, 0},
+		{"switch", 1, true, []CommandOption{WithConfirm()}, false, 1},
+		{"not bootable", 1, false, []CommandOption{WithConfirm()}, true, 0},
+		{"unknown slot", 3, true, []CommandOption{WithConfirm()}, true, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d, c := newFirmwareDevice(t)
+			d.slots[0].Bootable = tt.bootable
+
+			got, err := c.SetBootFirmware(context.Background(), tt.slot, tt.opts...)
+			require.Equal(t, tt.wantErr, err != nil, "SetBootFirmware() error = %v, wantErr %v", err, tt.wantErr)
+			require.Equal(t, tt.wantBootSlot, d.bootSlot)
+			if !tt.wantErr {
+				require.Equal(t, "8.2.0s036 build 4979", got.Version.String())
+			}
+		})
+	}
+}
+
+func TestClient_UpgradeFirmwareUnparsableSlot(t *testing.T) {
+	d, c := newFirmwareDevice(t)
+	d.slots[0].Version = "custom-build"
+
+	upgrade, err := c.UpgradeFirmware(context.Background(), "", WithDryRun())
+	require.NoError(t, err)
+	require.Equal(t, FirmwareUpgrade{State: FirmwareUpgradePlanned, Slot: 1}, upgrade)
+
+	slot, err := c.SetBootFirmware(context.Background(), 1, WithConfirm())
+	require.NoError(t, err)
+	require.Equal(t, "custom-build", slot.Raw)
+	require.Error(t, slot.VersionErr)
+	require.Equal(t, 1, d.bootSlot)
+}
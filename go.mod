@@ -4,13 +4,15 @@ go 1.21.0
 
 require (
 	github.com/go-resty/resty/v2 v2.7.0
+	github.com/gosnmp/gosnmp v1.37.0
 	github.com/jmespath/go-jmespath v0.4.0
 	github.com/stretchr/testify v1.8.4
+	golang.org/x/time v0.5.0
 )
 
 require (
 	github.com/davecgh/go-spew v1.1.1 // indirect
 	github.com/pmezard/go-difflib v1.0.0 // indirect
-	golang.org/x/net v0.0.0-20211029224645-99673261e6eb // indirect
+	golang.org/x/net v0.15.0 // indirect
 	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
@@ -0,0 +1,27 @@
+package peplink
+
+import (
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-resty/resty/v2"
+)
+
+// newTestClient returns the Client of the test server serving the handler
+// Only the HTTP client and the logger are set. The test sets the rest of the fields it needs
+func newTestClient(t *testing.T, handler http.HandlerFunc) *Client {
+	t.Helper()
+
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+
+	return &Client{
+		httpClient: resty.New().
+			SetBaseURL(srv.URL).
+			SetHeader("Content-Type", "application/json").
+			SetHeader("Accept", "application/json"),
+		log: slog.Default(),
+	}
+}
@@ -1,6 +1,19 @@
 package peplink
 
-import "time"
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/gosnmp/gosnmp"
+)
 
 type options struct {
 	httpBasicEndpoint string
@@ -9,11 +22,32 @@ type options struct {
 	timeout           time.Duration
 	snmpAddress       string
 	snmpCommunity     string
+	snmpV3            *SNMPv3Credentials
+	snmpOnly          bool
+	revokeOnClose     bool
+	tokenStore        TokenStore
+	authMode          authMode
+	username          string
+	password          string
+	tls               tlsOptions
+	httpClient        *http.Client
+	logger            *slog.Logger
+	logAttrs          []any
+	userAgent         string
+	proxy             string
+	retry             RetryPolicy
+	throttle          Throttle
+	rebootGrace       time.Duration
 }
 type Option func(*options) error
 
+// WithHTTPBasicURL sets the base URL of the device API. Scheme must be http or https
 func WithHTTPBasicURL(url string) Option {
 	return func(o *options) error {
+		err := validateHTTPBasicURL(url)
+		if err != nil {
+			return err
+		}
 		o.httpBasicEndpoint = url
 		return nil
 	}
@@ -33,15 +67,24 @@ func WithHTTPBasicClientSecret(secret string) Option {
 	}
 }
 
+// WithTimeout sets the timeout of the HTTP and SNMP requests. Must be positive
 func WithTimeout(timeout time.Duration) Option {
 	return func(o *options) error {
+		if timeout <= 0 {
+			return fmt.Errorf("%w: timeout must be positive, got %s", ErrInvalidOption, timeout)
+		}
 		o.timeout = timeout
 		return nil
 	}
 }
 
+// WithSNMPAddress sets the SNMP agent address in the host:port format
 func WithSNMPAddress(address string) Option {
 	return func(o *options) error {
+		err := validateSNMPAddress(address)
+		if err != nil {
+			return err
+		}
 		o.snmpAddress = address
 		return nil
 	}
@@ -53,3 +96,278 @@ func WithSNMPCommunity(community string) Option {
 		return nil
 	}
 }
+
+// WithSNMPv3 switches SNMP queries to SNMPv3 with the given credentials
+func WithSNMPv3(creds SNMPv3Credentials) Option {
+	return func(o *options) error {
+		if creds.Username == "" {
+			return fmt.Errorf("%w: snmpv3 username is empty", ErrInvalidOption)
+		}
+		err := applySNMPv3(&gosnmp.GoSNMP{}, &creds)
+		if err != nil {
+			return fmt.Errorf("%w: %w", ErrInvalidOption, err)
+		}
+		o.snmpV3 = &creds
+		return nil
+	}
+}
+
+// WithSNMPOnly skips the HTTP API authentication. Use it when the REST API is turned off on the device
+// Only *SNMP methods of the Client could be used then
+func WithSNMPOnly() Option {
+	return func(o *options) error {
+		o.snmpOnly = true
+		return nil
+	}
+}
+
+// WithRevokeTokenOnClose revokes the access token on the device when the Client is closed
+func WithRevokeTokenOnClose() Option {
+	return func(o *options) error {
+		o.revokeOnClose = true
+		return nil
+	}
+}
+
+// WithTokenStore reuses access tokens from the store instead of granting a new one on every NewClient
+func WithTokenStore(store TokenStore) Option {
+	return func(o *options) error {
+		o.tokenStore = store
+		return nil
+	}
+}
+
+// WithAdminLogin authenticates with the admin username/password via /api/login instead of the client ID grant
+// Use it for devices without an API client configured
+func WithAdminLogin(username, password string) Option {
+	return func(o *options) error {
+		if username == "" {
+			return fmt.Errorf("%w: admin username is empty", ErrInvalidOption)
+		}
+		o.authMode = authModeSession
+		o.username = username
+		o.password = password
+		return nil
+	}
+}
+
+// WithTLSRootCAs verifies the device certificate against the given CA pool instead of the system one
+func WithTLSRootCAs(pool *x509.CertPool) Option {
+	return func(o *options) error {
+		if pool == nil {
+			return fmt.Errorf("%w: tls root CA pool is nil", ErrInvalidOption)
+		}
+		o.tls.rootCAs = pool
+		return nil
+	}
+}
+
+// WithTLSPinnedSHA256 accepts only the device certificates with the given SHA-256 fingerprints (hex, colons allowed)
+// The chain isn't verified when pins are set, so self-signed certificates work
+func WithTLSPinnedSHA256(fingerprints ...string) Option {
+	return func(o *options) error {
+		if len(fingerprints) == 0 {
+			return fmt.Errorf("%w: no tls fingerprints to pin", ErrInvalidOption)
+		}
+		for _, fp := range fingerprints {
+			pin, err := parseFingerprint(fp)
+			if err != nil {
+				return fmt.Errorf("%w: %w", ErrInvalidOption, err)
+			}
+			o.tls.pins = append(o.tls.pins, pin)
+		}
+		return nil
+	}
+}
+
+// WithTLSInsecureSkipVerify disables the verification of the device certificate
+// Connections are open to man-in-the-middle attacks. Prefer WithTLSPinnedSHA256 or WithTLSRootCAs
+func WithTLSInsecureSkipVerify() Option {
+	return func(o *options) error {
+		o.tls.insecureSkipVerify = true
+		return nil
+	}
+}
+
+// WithTLSClientCertificate presents the certificate to devices requiring mutual TLS
+func WithTLSClientCertificate(cert tls.Certificate) Option {
+	return func(o *options) error {
+		if len(cert.Certificate) == 0 {
+			return fmt.Errorf("%w: tls client certificate is empty", ErrInvalidOption)
+		}
+		o.tls.clientCertificates = append(o.tls.clientCertificates, cert)
+		return nil
+	}
+}
+
+// WithHTTPClient sends the API requests through the given client, e.g. with an instrumented transport
+// The client is copied, so the timeout and the cookie jar set by the Client don't affect it
+// TLS and proxy options can't be combined with it. Configure them on the transport instead
+func WithHTTPClient(hc *http.Client) Option {
+	return func(o *options) error {
+		if hc == nil {
+			return fmt.Errorf("%w: http client is nil", ErrInvalidOption)
+		}
+		o.httpClient = hc
+		return nil
+	}
+}
+
+// WithLogger sets the logger of the Client. slog.Default() is used otherwise
+// The 'endpoint' attribute is added to every log line
+func WithLogger(logger *slog.Logger) Option {
+	return func(o *options) error {
+		if logger == nil {
+			return fmt.Errorf("%w: logger is nil", ErrInvalidOption)
+		}
+		o.logger = logger
+		return nil
+	}
+}
+
+// WithLogAttrs adds per-device attributes, e.g. "serial", to every log line of the Client
+// Arguments are key-value pairs or slog.Attr as in slog.Logger.With
+func WithLogAttrs(args ...any) Option {
+	return func(o *options) error {
+		o.logAttrs = append(o.logAttrs, args...)
+		return nil
+	}
+}
+
+// WithUserAgent sets the User-Agent header of the API requests
+func WithUserAgent(userAgent string) Option {
+	return func(o *options) error {
+		if userAgent == "" {
+			return fmt.Errorf("%w: user agent is empty", ErrInvalidOption)
+		}
+		o.userAgent = userAgent
+		return nil
+	}
+}
+
+// WithProxy sends the API requests through the proxy. Supported schemes: http, https, socks5
+func WithProxy(proxyURL string) Option {
+	return func(o *options) error {
+		u, err := url.Parse(proxyURL)
+		if err != nil {
+			return fmt.Errorf("%w: proxy url '%s': %w", ErrInvalidOption, proxyURL, err)
+		}
+		switch u.Scheme {
+		case "http", "https", "socks5":
+		default:
+			return fmt.Errorf("%w: proxy url '%s': scheme must be http, https or socks5", ErrInvalidOption, proxyURL)
+		}
+		if u.Host == "" {
+			return fmt.Errorf("%w: proxy url '%s': host is empty", ErrInvalidOption, proxyURL)
+		}
+		o.proxy = proxyURL
+		return nil
+	}
+}
+
+// WithRetryPolicy retries the failed API requests. Requests aren't retried by default
+func WithRetryPolicy(policy RetryPolicy) Option {
+	return func(o *options) error {
+		if policy.MinBackoff < 0 || policy.MaxBackoff < policy.MinBackoff {
+			return fmt.Errorf("%w: retry backoff must be 0 <= min <= max, got min=%s max=%s", ErrInvalidOption, policy.MinBackoff, policy.MaxBackoff)
+		}
+		o.retry = policy
+		return nil
+	}
+}
+
+// WithThrottle limits the request rate and the number of concurrent requests to the device
+func WithThrottle(t Throttle) Option {
+	return func(o *options) error {
+		if t.RequestsPerSecond < 0 || t.MaxInFlight < 0 || t.Burst < 0 {
+			return fmt.Errorf("%w: throttle values must not be negative", ErrInvalidOption)
+		}
+		o.throttle = t
+		return nil
+	}
+}
+
+// WithRebootGrace sets how long WaitReady waits for the device to go down after Reboot. 30s by default
+// Devices which reboot slowly need more. Must be positive
+func WithRebootGrace(grace time.Duration) Option {
+	return func(o *options) error {
+		if grace <= 0 {
+			return fmt.Errorf("%w: reboot grace must be positive, got %s", ErrInvalidOption, grace)
+		}
+		o.rebootGrace = grace
+		return nil
+	}
+}
+
+// validate checks the combination of the options. Returns all found problems joined
+func (o *options) validate() error {
+	var errs []error
+
+	err := validateHTTPBasicURL(o.httpBasicEndpoint)
+	if err != nil {
+		errs = append(errs, err)
+	}
+	if o.timeout <= 0 {
+		errs = append(errs, fmt.Errorf("%w: timeout must be positive, got %s", ErrInvalidOption, o.timeout))
+	}
+	err = validateSNMPAddress(o.snmpAddress)
+	if err != nil {
+		errs = append(errs, err)
+	}
+
+	if o.httpClient != nil && o.tls.config() != nil {
+		errs = append(errs, fmt.Errorf("%w: tls options can't be combined with a custom http client", ErrInvalidOption))
+	}
+	if o.httpClient != nil && o.proxy != "" {
+		errs = append(errs, fmt.Errorf("%w: proxy can't be combined with a custom http client", ErrInvalidOption))
+	}
+
+	if !o.snmpOnly {
+		switch o.authMode {
+		case authModeSession:
+			if o.username == "" {
+				errs = append(errs, fmt.Errorf("%w: admin username is empty", ErrInvalidOption))
+			}
+		default:
+			if o.httpClientID == "" {
+				errs = append(errs, fmt.Errorf("%w: http client ID is empty", ErrInvalidOption))
+			}
+			if o.httpClientSecret == "" {
+				errs = append(errs, fmt.Errorf("%w: http client secret is empty", ErrInvalidOption))
+			}
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func validateHTTPBasicURL(raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return fmt.Errorf("%w: http basic url '%s': %w", ErrInvalidOption, raw, err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("%w: http basic url '%s': scheme must be http or https", ErrInvalidOption, raw)
+	}
+	if u.Host == "" {
+		return fmt.Errorf("%w: http basic url '%s': host is empty", ErrInvalidOption, raw)
+	}
+
+	return nil
+}
+
+func validateSNMPAddress(address string) error {
+	host, port, err := net.SplitHostPort(address)
+	if err != nil {
+		return fmt.Errorf("%w: snmp address '%s': %w", ErrInvalidOption, address, err)
+	}
+	if host == "" {
+		return fmt.Errorf("%w: snmp address '%s': host is empty", ErrInvalidOption, address)
+	}
+	p, err := strconv.ParseUint(port, 10, 16)
+	if err != nil || p == 0 {
+		return fmt.Errorf("%w: snmp address '%s': port must be in [1, 65535]", ErrInvalidOption, address)
+	}
+
+	return nil
+}
@@ -0,0 +1,194 @@
+package peplink
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewClient_invalidOptions(t *testing.T) {
+	var requests atomic.Int32
+	srv := httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests.Add(1)
+		}),
+	)
+	defer srv.Close()
+
+	tests := []struct {
+		name    string
+		opts    []Option
+		wantErr []string
+	}{
+		{"no credentials",
+			[]Option{WithHTTPBasicURL(srv.URL)},
+			[]string{"http client ID is empty", "http client secret is empty"},
+		},
+		{"bad url scheme",
+			[]Option{WithHTTPBasicURL("ftp://192.168.50.1"), WithHTTPBasicClientID("id"), WithHTTPBasicClientSecret("secret")},
+			[]string{"scheme must be http or https"},
+		},
+		{"url without host",
+			[]Option{WithHTTPBasicURL("https://"), WithHTTPBasicClientID("id"), WithHTTPBasicClientSecret("secret")},
+			[]string{"host is empty"},
+		},
+		{"unparsable url",
+			[]Option{WithHTTPBasicURL("http://192.168.50.1:port"), WithHTTPBasicClientID("id"), WithHTTPBasicClientSecret("secret")},
+			[]string{"http basic url 'http://192.168.50.1:port'"},
+		},
+		{"zero timeout",
+			[]Option{WithHTTPBasicURL(srv.URL), WithHTTPBasicClientID("id"), WithHTTPBasicClientSecret("secret"), WithTimeout(0)},
+			[]string{"timeout must be positive"},
+		},
+		{"zero reboot grace",
+			[]Option{WithHTTPBasicURL(srv.URL), WithHTTPBasicClientID("id"), WithHTTPBasicClientSecret("secret"), WithRebootGrace(0)},
+			[]string{"reboot grace must be positive"},
+		},
+		{"snmp address without port",
+			[]Option{WithSNMPOnly(), WithSNMPAddress("192.168.50.1")},
+			[]string{"snmp address '192.168.50.1'"},
+		},
+		{"snmp address with bad port",
+			[]Option{WithSNMPOnly(), WithSNMPAddress("192.168.50.1:70000")},
+			[]string{"port must be in [1, 65535]"},
+		},
+		{"snmpv3 unknown protocol",
+			[]Option{WithSNMPOnly(), WithSNMPv3(SNMPv3Credentials{Username: "user", AuthProtocol: "SHA3"})},
+			[]string{"unknown snmpv3 auth protocol: SHA3"},
+		},
+		{"admin login without username",
+			[]Option{WithHTTPBasicURL(srv.URL), WithAdminLogin("", "secret")},
+			[]string{"admin username is empty"},
+		},
+		{"all errors are reported",
+			[]Option{WithHTTPBasicURL("192.168.50.1"), WithTimeout(-1), WithSNMPAddress(":161")},
+			[]string{"scheme must be http or https", "timeout must be positive", "snmp address ':161': host is empty", "http client ID is empty"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := NewClient(context.Background(), tt.opts...)
+			require.Nil(t, c)
+			require.ErrorIs(t, err, ErrInvalidOption)
+			for _, want := range tt.wantErr {
+				require.ErrorContains(t, err, want)
+			}
+			require.Equal(t, int32(0), requests.Load(), "NewClient must not touch the network with invalid options")
+		})
+	}
+}
+
+func TestNewClient_validOptions(t *testing.T) {
+	c, err := NewClient(context.Background(),
+		WithSNMPOnly(),
+		WithSNMPAddress("[::1]:1161"),
+		WithSNMPv3(SNMPv3Credentials{Username: "user", AuthProtocol: "sha256", AuthPassphrase: "pass", PrivProtocol: "AES", PrivPassphrase: "pass"}),
+		WithTimeout(1),
+	)
+	require.NoError(t, err)
+	require.Equal(t, "[::1]:1161", c.snmp.address)
+}
+
+type countingTransport struct {
+	requests atomic.Int32
+}
+
+func (t *countingTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	t.requests.Add(1)
+	return http.DefaultTransport.RoundTrip(r)
+}
+
+func TestNewClient_transportOptions(t *testing.T) {
+	var userAgent atomic.Value
+	srv := httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			userAgent.Store(r.UserAgent())
+			tokenGrantHandler(t)(w, r)
+		}),
+	)
+	defer srv.Close()
+
+	credentials := []Option{
+		WithHTTPBasicURL(srv.URL),
+		WithHTTPBasicClientID("client_id"),
+		WithHTTPBasicClientSecret("client_secret"),
+	}
+
+	t.Run("http client and user agent", func(t *testing.T) {
+		transport := &countingTransport{}
+		hc := &http.Client{Transport: transport}
+
+		c, err := NewClient(context.Background(), append(credentials, WithHTTPClient(hc), WithUserAgent("fleet-exporter/1.0"))...)
+		require.NoError(t, err)
+		defer c.Close(context.Background())
+
+		require.Equal(t, int32(1), transport.requests.Load())
+		require.Equal(t, "fleet-exporter/1.0", userAgent.Load())
+		require.Nil(t, hc.Jar, "caller's client must stay untouched")
+		require.Zero(t, hc.Timeout, "caller's client must stay untouched")
+	})
+
+	t.Run("logger with device attributes", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		logger := slog.New(slog.NewJSONHandler(buf, nil))
+
+		c, err := NewClient(context.Background(), append(credentials, WithLogger(logger), WithLogAttrs("serial", "1111-2222-3333"))...)
+		require.NoError(t, err)
+		require.NoError(t, c.Close(context.Background()))
+
+		lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+		require.NotEmpty(t, lines)
+		for _, line := range lines {
+			rec := map[string]any{}
+			require.NoError(t, json.Unmarshal([]byte(line), &rec))
+			require.Equal(t, srv.URL, rec["endpoint"], line)
+			require.Equal(t, "1111-2222-3333", rec["serial"], line)
+		}
+	})
+
+	t.Run("proxy", func(t *testing.T) {
+		var proxied atomic.Value
+		proxy := httptest.NewServer(
+			http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				proxied.Store(r.Host)
+				tokenGrantHandler(t)(w, r)
+			}),
+		)
+		defer proxy.Close()
+
+		c, err := NewClient(context.Background(),
+			WithHTTPBasicURL("http://peplink.remote-site.invalid"),
+			WithHTTPBasicClientID("client_id"),
+			WithHTTPBasicClientSecret("client_secret"),
+			WithProxy(proxy.URL),
+		)
+		require.NoError(t, err)
+		defer c.Close(context.Background())
+
+		require.Equal(t, "peplink.remote-site.invalid", proxied.Load())
+	})
+
+	t.Run("conflicting options", func(t *testing.T) {
+		_, err := NewClient(context.Background(), append(credentials,
+			WithHTTPClient(&http.Client{}),
+			WithTLSInsecureSkipVerify(),
+			WithProxy("socks5://127.0.0.1:1080"),
+		)...)
+		require.ErrorIs(t, err, ErrInvalidOption)
+		require.ErrorContains(t, err, "tls options can't be combined")
+		require.ErrorContains(t, err, "proxy can't be combined")
+	})
+
+	t.Run("invalid proxy", func(t *testing.T) {
+		_, err := NewClient(context.Background(), append(credentials, WithProxy("ftp://127.0.0.1"))...)
+		require.ErrorIs(t, err, ErrInvalidOption)
+	})
+}
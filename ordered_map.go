@@ -0,0 +1,107 @@
+package peplink
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+)
+
+// OrderedItem is the entry of the OrderedMap with its ID
+type OrderedItem[T any] struct {
+	ID    int
+	Value T
+}
+
+// OrderedMap decodes the Peplink "order"-keyed objects in one pass:
+//
+//	{"1": {...}, "2": {...}, "order": [1, 2]}
+//
+// Items keep the "order" of the response. Other non-numeric keys are ignored
+// If "order" is absent the items are sorted by ID
+type OrderedMap[T any] []OrderedItem[T]
+
+// OrderedMapError reports the ID of the item which failed to decode
+type OrderedMapError struct {
+	ID  int
+	Err error
+}
+
+func (e *OrderedMapError) Error() string {
+	return fmt.Sprintf("failed to decode item with id %d: %v", e.ID, e.Err)
+}
+
+func (e *OrderedMapError) Unwrap() error {
+	return e.Err
+}
+
+func (m *OrderedMap[T]) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		*m = nil
+		return nil
+	}
+
+	raw := map[string]json.RawMessage{}
+	err := json.Unmarshal(data, &raw)
+	if err != nil {
+		return fmt.Errorf("failed to decode ordered map: %w", err)
+	}
+
+	var order []int
+	if o, ok := raw["order"]; ok {
+		err = json.Unmarshal(o, &order)
+		if err != nil {
+			return fmt.Errorf("failed to decode order: %w", err)
+		}
+	} else {
+		for k := range raw {
+			if id, err := strconv.Atoi(k); err == nil {
+				order = append(order, id)
+			}
+		}
+		sort.Ints(order)
+	}
+
+	items := make(OrderedMap[T], 0, len(order))
+	for _, id := range order {
+		msg, ok := raw[strconv.Itoa(id)]
+		if !ok {
+			return &OrderedMapError{ID: id, Err: fmt.Errorf("id is in the order but not in the object")}
+		}
+		var v T
+		err = unmarshalJSON(msg, &v)
+		if err != nil {
+			return &OrderedMapError{ID: id, Err: err}
+		}
+		items = append(items, OrderedItem[T]{ID: id, Value: v})
+	}
+	*m = items
+
+	return nil
+}
+
+// Values returns the items in the order. nil if the map is empty
+func (m OrderedMap[T]) Values() []T {
+	if len(m) == 0 {
+		return nil
+	}
+
+	values := make([]T, 0, len(m))
+	for _, item := range m {
+		values = append(values, item.Value)
+	}
+
+	return values
+}
+
+// Get returns the item by ID
+func (m OrderedMap[T]) Get(id int) (T, bool) {
+	for _, item := range m {
+		if item.ID == id {
+			return item.Value, true
+		}
+	}
+
+	var zero T
+	return zero, false
+}
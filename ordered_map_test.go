@@ -0,0 +1,175 @@
+package peplink
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/jmespath/go-jmespath"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOrderedMap_UnmarshalJSON(t *testing.T) {
+	type item struct {
+		Name string `json:"name"`
+	}
+	tests := []struct {
+		name    string
+		data    string
+		want    OrderedMap[item]
+		wantErr bool
+		errID   int
+	}{
+		{"keeps order",
+			`{"2": {"name": "WAN 2"}, "1": {"name": "WAN 1"}, "order": [2, 1]}`,
+			OrderedMap[item]{{ID: 2, Value: item{Name: "WAN 2"}}, {ID: 1, Value: item{Name: "WAN 1"}}},
+			false, 0,
+		},
+		{"sorted by id without order",
+			`{"10": {"name": "WAN 10"}, "2": {"name": "WAN 2"}, "extra": true}`,
+			OrderedMap[item]{{ID: 2, Value: item{Name: "WAN 2"}}, {ID: 10, Value: item{Name: "WAN 10"}}},
+			false, 0,
+		},
+		{"ignores ids out of order",
+			`{"1": {"name": "WAN 1"}, "2": {"name": "WAN 2"}, "order": [1]}`,
+			OrderedMap[item]{{ID: 1, Value: item{Name: "WAN 1"}}},
+			false, 0,
+		},
+		{"empty",
+			`{"order": []}`,
+			OrderedMap[item]{},
+			false, 0,
+		},
+		{"null",
+			`null`,
+			nil,
+			false, 0,
+		},
+		{"id missing in the object",
+			`{"1": {"name": "WAN 1"}, "order": [1, 3]}`,
+			nil,
+			true, 3,
+		},
+		{"bad item",
+			`{"1": {"name": "WAN 1"}, "2": {"name": 2}, "order": [1, 2]}`,
+			nil,
+			true, 2,
+		},
+		{"bad order",
+			`{"1": {"name": "WAN 1"}, "order": "1"}`,
+			nil,
+			true, 0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got OrderedMap[item]
+			err := json.Unmarshal([]byte(tt.data), &got)
+			require.Equal(t, tt.wantErr, err != nil, "UnmarshalJSON() error = %v, wantErr %v", err, tt.wantErr)
+			if tt.errID != 0 {
+				mapErr := &OrderedMapError{}
+				require.True(t, errors.As(err, &mapErr))
+				require.Equal(t, tt.errID, mapErr.ID)
+			}
+			if !tt.wantErr {
+				require.Equal(t, tt.want, got)
+			}
+		})
+	}
+}
+
+func TestOrderedMap_ValuesAndGet(t *testing.T) {
+	m := OrderedMap[string]{{ID: 2, Value: "b"}, {ID: 1, Value: "a"}}
+
+	require.Equal(t, []string{"b", "a"}, m.Values())
+	require.Nil(t, OrderedMap[string]{}.Values())
+
+	v, ok := m.Get(1)
+	require.True(t, ok)
+	require.Equal(t, "a", v)
+
+	_, ok = m.Get(3)
+	require.False(t, ok)
+}
+
+// benchmarkWanStatusResponse builds the status.wan.connection response with n cellular WANs
+func benchmarkWanStatusResponse(n int) []byte {
+	items := make([]string, 0, n)
+	order := make([]string, 0, n)
+	for i := 1; i <= n; i++ {
+		items = append(items, fmt.Sprintf(`"%d": {
+			"name": "WAN %d", "enable": true, "statusLed": "green", "message": "Connected",
+			"uptime": 3600, "type": "cellular", "priority": 1, "ip": "10.0.0.%d",
+			"cellular": {
+				"mobileType": "LTE", "modulePowerOn": true, "imei": "123456789012345",
+				"sim": {
+					"1": {"simCardDetected": true, "simCardUsed": true, "imsi": "1", "iccid": "1"},
+					"2": {"simCardDetected": false, "simCardUsed": false},
+					"order": [1, 2]
+				},
+				"signalLevel": 4, "rat": [{"name": "LTE", "band": [{"name": "B3", "signal": {"rssi": -60, "rsrp": -90, "rsrq": -10, "sinr": 12}}]}]
+			}
+		}`, i, i, i))
+		order = append(order, fmt.Sprint(i))
+	}
+
+	return []byte(fmt.Sprintf(`{%s, "order": [%s]}`, strings.Join(items, ","), strings.Join(order, ",")))
+}
+
+// legacyDecodeOrdered is the jmespath based decoding which OrderedMap replaced
+func legacyDecodeOrdered(msg []byte) ([]WanStatus, error) {
+	var buf interface{}
+	err := json.Unmarshal(msg, &buf)
+	if err != nil {
+		return nil, err
+	}
+	orderI, err := jmespath.Search("order", buf)
+	if err != nil {
+		return nil, err
+	}
+	statuses := []WanStatus{}
+	for _, oi := range orderI.([]interface{}) {
+		statusI, err := jmespath.Search(fmt.Sprintf("\"%d\"", int(oi.(float64))), buf)
+		if err != nil {
+			return nil, err
+		}
+		b, err := json.Marshal(statusI)
+		if err != nil {
+			return nil, err
+		}
+		status := WanStatus{}
+		err = json.Unmarshal(b, &status)
+		if err != nil {
+			return nil, err
+		}
+		statuses = append(statuses, status)
+	}
+
+	return statuses, nil
+}
+
+func BenchmarkOrderedMap(b *testing.B) {
+	msg := benchmarkWanStatusResponse(8)
+
+	b.Run("OrderedMap", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			var m OrderedMap[WanStatus]
+			err := json.Unmarshal(msg, &m)
+			if err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("jmespath", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			_, err := legacyDecodeOrdered(msg)
+			if err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
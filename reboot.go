@@ -0,0 +1,86 @@
+package peplink
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Reboot reboots the device
+// Requires WithConfirm. WithDryRun only checks that the API answers
+// Use WaitReady to wait until the device is back
+func (c *Client) Reboot(ctx context.Context, opts ...CommandOption) error {
+	o, err := newCommandOptions("reboot", opts)
+	if err != nil {
+		return err
+	}
+
+	if o.dryRun {
+		_, err = c.Firmwares(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to reboot: %w", err)
+		}
+		return nil
+	}
+
+	c.log.Info("Rebooting Peplink device")
+	_, err = c.doRequest(ctx, postRequest("/api/cmd.system.reboot", nil))
+	if err != nil {
+		return fmt.Errorf("failed to reboot: %w", err)
+	}
+	c.rebootedAt.Store(time.Now().UnixNano())
+	// The device may boot the firmware of another slot
+	c.forgetFirmware()
+
+	return nil
+}
+
+// WaitReady polls the device until the API answers again and returns how long the outage lasted
+// The outage is counted from Reboot, or from the first failed probe if Reboot wasn't called
+// After Reboot the device is ready only once a probe failed. Fails if it keeps answering for the reboot grace, see WithRebootGrace
+// Rejected credentials are renewed on the way. Returns when ctx is done
+func (c *Client) WaitReady(ctx context.Context) (time.Duration, error) {
+	var since time.Time
+	if ns := c.rebootedAt.Load(); ns != 0 {
+		since = time.Unix(0, ns)
+	}
+	down := false
+
+	var lastErr error
+	for attempt := 1; ; attempt++ {
+		_, err := c.doThrottledRequest(ctx, getRequest("/api/info.frw.version"))
+		now := time.Now()
+		switch {
+		case err == nil && !down && !since.IsZero():
+			if now.Sub(since) >= c.rebootGrace {
+				c.rebootedAt.Store(0)
+				return 0, fmt.Errorf("failed to wait for the device: it still answers %s after the reboot", c.rebootGrace)
+			}
+		case err == nil:
+			var outage time.Duration
+			if !since.IsZero() {
+				outage = now.Sub(since)
+			}
+			c.rebootedAt.Store(0)
+			c.log.Info("Peplink device is ready", "outage", outage.String())
+			return outage, nil
+		case err != nil:
+			if c.closed.Load() {
+				return 0, ErrClientClosed
+			}
+			if !down {
+				down = true
+				if since.IsZero() {
+					since = now
+				}
+			}
+			lastErr = err
+			c.log.Debug("Peplink device is not ready", "attempt", attempt, "error", err)
+		}
+
+		serr := sleepCtx(ctx, c.readyPoll.duration(attempt))
+		if serr != nil {
+			return 0, fmt.Errorf("failed to wait for the device: %w (last error: %v)", serr, lastErr)
+		}
+	}
+}
@@ -0,0 +1,135 @@
+package peplink
+
+import (
+	"context"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// rebootHandler drops the connections for downFor after the reboot command
+// The token issued before the reboot is rejected afterwards
+func rebootHandler(downFor time.Duration, reboots, grants *atomic.Int32) http.HandlerFunc {
+	var upAt atomic.Int64 // unix nanoseconds when the device answers again. 0 until the reboot
+	return func(w http.ResponseWriter, r *http.Request) {
+		if time.Now().UnixNano() < upAt.Load() {
+			conn, _, err := w.(http.Hijacker).Hijack()
+			if err == nil {
+				conn.Close()
+			}
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.URL.Path == "/api/auth.token.grant":
+			grants.Add(1)
+			w.Write([]byte(`{"stat": "ok", "response": {"accessToken": "fresh", "expiresIn": "172800"}}`))
+		case upAt.Load() != 0 && r.URL.Query().Get("accessToken") != "fresh":
+			w.Write([]byte(`{"stat": "fail", "code": 401, "message": "Unauthorized"}`))
+		case r.URL.Path == "/api/cmd.system.reboot":
+			reboots.Add(1)
+			upAt.Store(time.Now().Add(downFor).UnixNano())
+			w.Write([]byte(`{"stat": "ok"}`))
+		default:
+			w.Write([]byte(`{"stat": "ok", "response": {"1": {"version": "8.3.0 build 5229", "bootable": true, "inUse": true}, "order": [1]}}`))
+		}
+	}
+}
+
+// newRebootTestClient returns the Client which can renew the token and probes the device every few milliseconds
+func newRebootTestClient(t *testing.T, handler http.HandlerFunc) *Client {
+	t.Helper()
+
+	c := newTestClient(t, handler)
+	c.clientID = "client_id"
+	c.clientSecret = "client_secret"
+	c.token = "token"
+	c.rebootGrace = time.Second
+	c.readyPoll = backoff{min: 5 * time.Millisecond, max: 20 * time.Millisecond}
+
+	return c
+}
+
+func TestClient_Reboot(t *testing.T) {
+	tests := []struct {
+		name        string
+		opts        []CommandOption
+		wantErr     error
+		wantReboots int32
+	}{
+		{"not confirmed", nil, ErrNotConfirmed, 0},
+		{"dry run", []CommandOption{WithDryRun()}, nil, 0},
+		{"confirmed", []CommandOption{WithConfirm()}, nil, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var reboots, grants atomic.Int32
+			c := newRebootTestClient(t, rebootHandler(50*time.Millisecond, &reboots, &grants))
+
+			err := c.Reboot(context.Background(), tt.opts...)
+			if tt.wantErr != nil {
+				require.ErrorIs(t, err, tt.wantErr)
+			} else {
+				require.NoError(t, err)
+			}
+			require.Equal(t, tt.wantReboots, reboots.Load())
+		})
+	}
+}
+
+func TestClient_WaitReady(t *testing.T) {
+	t.Run("after reboot", func(t *testing.T) {
+		var reboots, grants atomic.Int32
+		c := newRebootTestClient(t, rebootHandler(100*time.Millisecond, &reboots, &grants))
+		require.NoError(t, c.Reboot(context.Background(), WithConfirm()))
+
+		outage, err := c.WaitReady(context.Background())
+		require.NoError(t, err)
+		require.GreaterOrEqual(t, outage, 100*time.Millisecond)
+		require.Less(t, outage, time.Second)
+		require.Equal(t, int32(1), grants.Load(), "rejected token must be renewed")
+		require.Equal(t, "fresh", c.accessToken())
+	})
+
+	t.Run("already up", func(t *testing.T) {
+		var reboots, grants atomic.Int32
+		c := newRebootTestClient(t, rebootHandler(0, &reboots, &grants))
+
+		outage, err := c.WaitReady(context.Background())
+		require.NoError(t, err)
+		require.Zero(t, outage)
+	})
+
+	t.Run("never down", func(t *testing.T) {
+		var reboots, grants atomic.Int32
+		c := newRebootTestClient(t, rebootHandler(0, &reboots, &grants))
+		c.rebootGrace = 50 * time.Millisecond
+		require.NoError(t, c.Reboot(context.Background(), WithConfirm()))
+		require.Equal(t, int32(1), reboots.Load())
+
+		start := time.Now()
+		outage, err := c.WaitReady(context.Background())
+		require.ErrorContains(t, err, "still answers 50ms after the reboot")
+		require.Zero(t, outage)
+		require.GreaterOrEqual(t, time.Since(start), 40*time.Millisecond, "device answering right after the reboot isn't ready")
+
+		outage, err = c.WaitReady(context.Background())
+		require.NoError(t, err, "the reboot is forgotten after the failure")
+		require.Zero(t, outage)
+	})
+
+	t.Run("never back", func(t *testing.T) {
+		var reboots, grants atomic.Int32
+		c := newRebootTestClient(t, rebootHandler(time.Hour, &reboots, &grants))
+		require.NoError(t, c.Reboot(context.Background(), WithConfirm()))
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+		_, err := c.WaitReady(ctx)
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+}
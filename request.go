@@ -0,0 +1,117 @@
+package peplink
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sync/atomic"
+)
+
+// httpMethod is the HTTP method of the API request
+// Only the values below exist, so an unsupported method doesn't compile
+type httpMethod struct {
+	name       string
+	idempotent bool // Safe to retry without WithNonIdempotentRetry
+}
+
+var (
+	methodGet    = httpMethod{name: http.MethodGet, idempotent: true}
+	methodPost   = httpMethod{name: http.MethodPost}
+	methodPut    = httpMethod{name: http.MethodPut, idempotent: true}
+	methodDelete = httpMethod{name: http.MethodDelete, idempotent: true}
+)
+
+func (m httpMethod) String() string {
+	return m.name
+}
+
+// apiRequest describes the call of the API endpoint
+type apiRequest struct {
+	method   httpMethod
+	endpoint string
+	query    url.Values
+	body     any
+	upload   *fileUpload // sent as multipart/form-data instead of the body
+}
+
+// fileUpload is the file of the multipart request
+// The data is kept in memory so the request can be replayed after re-authentication
+type fileUpload struct {
+	field string
+	name  string
+	data  []byte
+}
+
+func getRequest(endpoint string) apiRequest {
+	return apiRequest{method: methodGet, endpoint: endpoint}
+}
+
+func postRequest(endpoint string, body any) apiRequest {
+	return apiRequest{method: methodPost, endpoint: endpoint, body: body}
+}
+
+func putRequest(endpoint string, body any) apiRequest {
+	return apiRequest{method: methodPut, endpoint: endpoint, body: body}
+}
+
+func deleteRequest(endpoint string) apiRequest {
+	return apiRequest{method: methodDelete, endpoint: endpoint}
+}
+
+func uploadRequest(endpoint, field, name string, data []byte) apiRequest {
+	return apiRequest{method: methodPost, endpoint: endpoint, upload: &fileUpload{field: field, name: name, data: data}}
+}
+
+// withQuery adds the query parameter. Multiple values are added as repeated parameters
+func (r apiRequest) withQuery(key string, values ...string) apiRequest {
+	q := url.Values{}
+	for k, v := range r.query {
+		q[k] = append([]string(nil), v...)
+	}
+	for _, v := range values {
+		q.Add(key, v)
+	}
+	r.query = q
+
+	return r
+}
+
+// doRequestInto calls the API endpoint and decodes the response into T
+func doRequestInto[T any](ctx context.Context, c *Client, req apiRequest) (T, error) {
+	var v T
+
+	msg, err := c.doRequest(ctx, req)
+	if err != nil {
+		return v, err
+	}
+	if len(msg) == 0 || string(msg) == "null" {
+		return v, nil
+	}
+
+	err = unmarshalJSON(msg, &v)
+	if err != nil {
+		return v, fmt.Errorf("failed to decode response of %s: %w", req.endpoint, err)
+	}
+
+	return v, nil
+}
+
+// strictDecoding makes the response decoding fail on the unknown fields
+// The tests enable it to catch the drift between the models and the firmware output
+var strictDecoding atomic.Bool
+
+// unmarshalJSON is json.Unmarshal which honors strictDecoding
+// The custom UnmarshalJSON methods must use it to keep the strict mode for the nested objects
+func unmarshalJSON(data []byte, v any) error {
+	if !strictDecoding.Load() {
+		return json.Unmarshal(data, v)
+	}
+
+	d := json.NewDecoder(bytes.NewReader(data))
+	d.DisallowUnknownFields()
+
+	return d.Decode(v)
+}
@@ -0,0 +1,112 @@
+package peplink
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDoRequestInto(t *testing.T) {
+	type result struct {
+		ID   int    `json:"id"`
+		Name string `json:"name"`
+	}
+	tests := []struct {
+		name      string
+		req       apiRequest
+		wantQuery map[string][]string
+		wantBody  string
+		response  string
+		want      result
+		wantErr   bool
+	}{
+		{"get with query",
+			getRequest("/api/status.wan.connection").withQuery("id", "1 2").withQuery("lite", "yes"),
+			map[string][]string{"id": {"1 2"}, "lite": {"yes"}, "accessToken": {"token"}},
+			"",
+			`{"stat": "ok", "response": {"id": 1, "name": "WAN 1"}}`,
+			result{ID: 1, Name: "WAN 1"},
+			false,
+		},
+		{"put with body",
+			putRequest("/api/config.wan.connection", map[string]any{"id": 1, "enable": false}),
+			map[string][]string{"accessToken": {"token"}},
+			`{"enable":false,"id":1}`,
+			`{"stat": "ok", "response": {"id": 1, "name": "WAN 1"}}`,
+			result{ID: 1, Name: "WAN 1"},
+			false,
+		},
+		{"delete without response",
+			deleteRequest("/api/config.wan.connection").withQuery("id", "1"),
+			map[string][]string{"id": {"1"}, "accessToken": {"token"}},
+			"",
+			`{"stat": "ok"}`,
+			result{},
+			false,
+		},
+		{"post with unexpected response",
+			postRequest("/api/cmd.config.apply", nil),
+			map[string][]string{"accessToken": {"token"}},
+			"",
+			`{"stat": "ok", "response": [1, 2]}`,
+			result{},
+			true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+				require.Equal(t, tt.req.method.String(), r.Method)
+				require.Equal(t, tt.req.endpoint, r.URL.Path)
+				require.Equal(t, tt.wantQuery, map[string][]string(r.URL.Query()))
+				body, err := io.ReadAll(r.Body)
+				require.NoError(t, err)
+				if tt.wantBody == "" {
+					require.Empty(t, body)
+				} else {
+					require.JSONEq(t, tt.wantBody, string(body))
+				}
+				w.Header().Set("Content-Type", "application/json")
+				w.Write([]byte(tt.response))
+			})
+			c.token = "token"
+
+			got, err := doRequestInto[result](context.Background(), c, tt.req)
+			require.Equal(t, tt.wantErr, err != nil, "doRequestInto() error = %v, wantErr %v", err, tt.wantErr)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestApiRequest_withQueryCopies(t *testing.T) {
+	base := getRequest("/api/status.wan.connection").withQuery("lite", "yes")
+	a := base.withQuery("id", "1")
+	b := base.withQuery("id", "2")
+
+	require.Equal(t, "lite=yes", base.query.Encode())
+	require.Equal(t, "id=1&lite=yes", a.query.Encode())
+	require.Equal(t, "id=2&lite=yes", b.query.Encode())
+}
+
+// strict enables strictDecoding for the test
+func strict(t *testing.T) {
+	t.Helper()
+
+	strictDecoding.Store(true)
+	t.Cleanup(func() { strictDecoding.Store(false) })
+}
+
+func TestUnmarshalJSON_strict(t *testing.T) {
+	data := []byte(`{"1": {"name": "WAN 1", "newField": true}, "order": [1]}`)
+
+	var lax OrderedMap[WanStatus]
+	require.NoError(t, unmarshalJSON(data, &lax))
+
+	strict(t)
+	var got OrderedMap[WanStatus]
+	err := unmarshalJSON(data, &got)
+	require.ErrorContains(t, err, `unknown field "newField"`)
+}
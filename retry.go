@@ -0,0 +1,99 @@
+package peplink
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+)
+
+// RetryPolicy configures retries of the failed API requests
+// Network errors, HTTP 5xx, rate limiting and RetryCodes are retried. Waits for the Throttle are not
+// Non-idempotent requests (POST commands) are retried only with WithNonIdempotentRetry
+type RetryPolicy struct {
+	MaxAttempts int           // Attempts including the first one. Values below 2 disable retries
+	MinBackoff  time.Duration // Delay before the first retry. Doubled on every next one
+	MaxBackoff  time.Duration // Upper bound of the delay
+	RetryCodes  []int         // Peplink error codes to retry
+}
+
+type nonIdempotentRetryKey struct{}
+
+// WithNonIdempotentRetry allows retries of the non-idempotent requests made with the returned context
+// Use it for the commands which are safe to repeat
+func WithNonIdempotentRetry(ctx context.Context) context.Context {
+	return context.WithValue(ctx, nonIdempotentRetryKey{}, true)
+}
+
+func (p RetryPolicy) backoff() backoff {
+	return backoff{min: p.MinBackoff, max: p.MaxBackoff}
+}
+
+// retryDelay returns the delay before the next attempt and whether the request should be retried
+func (c *Client) retryDelay(ctx context.Context, method httpMethod, attempt int, err error) (time.Duration, bool) {
+	if attempt >= c.retry.MaxAttempts || ctx.Err() != nil || errors.Is(err, ErrClientClosed) {
+		return 0, false
+	}
+	var throttleErr *throttleError
+	if errors.As(err, &throttleErr) {
+		return 0, false
+	}
+	if !method.idempotent {
+		if allowed, _ := ctx.Value(nonIdempotentRetryKey{}).(bool); !allowed {
+			return 0, false
+		}
+	}
+
+	wait := c.retry.backoff().duration(attempt)
+
+	apiErr := &APIError{}
+	if errors.As(err, &apiErr) {
+		retriable := apiErr.StatusCode >= http.StatusInternalServerError ||
+			errors.Is(apiErr, ErrRateLimited) ||
+			slices.Contains(c.retry.RetryCodes, apiErr.Code)
+		if !retriable {
+			return 0, false
+		}
+		if apiErr.RetryAfter > wait {
+			wait = apiErr.RetryAfter
+		}
+	}
+
+	// Don't wait for the attempt which can't finish in time
+	if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < wait {
+		return 0, false
+	}
+
+	return wait, true
+}
+
+// parseRetryAfter decodes the Retry-After header in seconds or HTTP-date. Returns 0 if it is absent or invalid
+func parseRetryAfter(header string) time.Duration {
+	if header == "" {
+		return 0
+	}
+	if secs, err := strconv.Atoi(header); err == nil && secs > 0 {
+		return time.Duration(secs) * time.Second
+	}
+	if at, err := http.ParseTime(header); err == nil {
+		if d := time.Until(at); d > 0 {
+			return d
+		}
+	}
+
+	return 0
+}
+
+func sleepCtx(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,91 @@
+package peplink
+
+import (
+	"context"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_doRequestRetry(t *testing.T) {
+	policy := RetryPolicy{
+		MaxAttempts: 3,
+		MinBackoff:  time.Millisecond,
+		MaxBackoff:  5 * time.Millisecond,
+		RetryCodes:  []int{1001},
+	}
+	tests := []struct {
+		name         string
+		method       httpMethod
+		ctx          func(t *testing.T) context.Context
+		policy       RetryPolicy
+		failures     int32
+		status       int
+		response     string
+		retryAfter   string
+		wantAttempts int32
+		wantErr      bool
+		minDuration  time.Duration
+	}{
+		{"5xx recovered", methodGet, background, policy,
+			2, http.StatusBadGateway, `Bad Gateway`, "", 3, false, 0},
+		{"5xx exhausted", methodGet, background, policy,
+			5, http.StatusServiceUnavailable, `{"stat": "fail", "code": 503, "message": "Busy"}`, "", 3, true, 0},
+		{"peplink code", methodGet, background, policy,
+			1, http.StatusOK, `{"stat": "fail", "code": 1001, "message": "Busy"}`, "", 2, false, 0},
+		{"not retriable code", methodGet, background, policy,
+			1, http.StatusOK, `{"stat": "fail", "code": 400, "message": "Invalid parameter"}`, "", 1, true, 0},
+		{"retry after", methodGet, background, policy,
+			1, http.StatusTooManyRequests, `{"stat": "fail", "code": 429, "message": "Too Many Requests"}`, "1", 2, false, time.Second},
+		{"retry after beyond deadline", methodGet, func(t *testing.T) context.Context {
+			ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
+			t.Cleanup(cancel)
+			return ctx
+		}, policy,
+			1, http.StatusTooManyRequests, `{"stat": "fail", "code": 429, "message": "Too Many Requests"}`, "60", 1, true, 0},
+		{"post isn't retried", methodPost, background, policy,
+			1, http.StatusServiceUnavailable, `{"stat": "fail", "code": 503, "message": "Busy"}`, "", 1, true, 0},
+		{"post opt-in", methodPost, func(t *testing.T) context.Context { return WithNonIdempotentRetry(context.Background()) }, policy,
+			1, http.StatusServiceUnavailable, `{"stat": "fail", "code": 503, "message": "Busy"}`, "", 2, false, 0},
+		{"disabled by default", methodGet, background, RetryPolicy{},
+			1, http.StatusServiceUnavailable, `{"stat": "fail", "code": 503, "message": "Busy"}`, "", 1, true, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var attempts atomic.Int32
+			c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+				require.Equal(t, tt.method.String(), r.Method)
+				w.Header().Set("Content-Type", "application/json")
+				if attempts.Add(1) <= tt.failures {
+					if tt.retryAfter != "" {
+						w.Header().Set("Retry-After", tt.retryAfter)
+					}
+					w.WriteHeader(tt.status)
+					w.Write([]byte(tt.response))
+					return
+				}
+				w.Write([]byte(`{"stat": "ok", "response": {}}`))
+			})
+			c.retry = tt.policy
+
+			start := time.Now()
+			_, err := c.doRequest(tt.ctx(t), apiRequest{method: tt.method, endpoint: "/api/cmd.test"})
+			require.Equal(t, tt.wantErr, err != nil, "doRequest() error = %v, wantErr %v", err, tt.wantErr)
+			require.Equal(t, tt.wantAttempts, attempts.Load())
+			require.GreaterOrEqual(t, time.Since(start), tt.minDuration)
+		})
+	}
+}
+
+func background(*testing.T) context.Context { return context.Background() }
+
+func TestParseRetryAfter(t *testing.T) {
+	require.Equal(t, time.Duration(0), parseRetryAfter(""))
+	require.Equal(t, time.Duration(0), parseRetryAfter("soon"))
+	require.Equal(t, 120*time.Second, parseRetryAfter("120"))
+	d := parseRetryAfter(time.Now().Add(time.Minute).UTC().Format(http.TimeFormat))
+	require.InDelta(t, float64(time.Minute), float64(d), float64(2*time.Second))
+}
@@ -0,0 +1,98 @@
+package peplink
+
+import (
+	"context"
+	"fmt"
+)
+
+// authMode selects how the Client authenticates against the API
+type authMode int
+
+const (
+	// authModeToken uses the clientId/clientSecret token grant. The token is passed as the accessToken query parameter
+	authModeToken authMode = iota
+	// authModeSession logs in with the admin username/password. The session is kept in the cookie jar
+	authModeSession
+)
+
+// sessionCookieName is the cookie the device sets on successful /api/login
+const sessionCookieName = "bauth"
+
+// login starts a new admin session. The session cookie is stored in the cookie jar of the HTTP client
+func (c *Client) login(ctx context.Context) error {
+	type loginRequest struct {
+		Username string `json:"username"`
+		Password string `json:"password"`
+	}
+	envelope := &apiEnvelope{}
+
+	err := c.throttle.acquireAuth(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to login: %w", err)
+	}
+
+	rr, err := c.httpClient.R().
+		SetContext(ctx).
+		SetBody(loginRequest{
+			Username: c.username,
+			Password: c.password,
+		}).
+		SetResult(envelope).
+		SetError(envelope).
+		Post("/api/login")
+	if err != nil {
+		return fmt.Errorf("failed to login: %w", err)
+	}
+
+	if envelope.Stat != "ok" {
+		return fmt.Errorf("failed to login: %w", newAPIError("/api/login", rr, envelope))
+	}
+
+	session := ""
+	for _, ck := range rr.Cookies() {
+		if ck.Name == sessionCookieName {
+			session = ck.Value
+		}
+	}
+	if session == "" {
+		return fmt.Errorf("failed to login: no '%s' cookie in the response", sessionCookieName)
+	}
+
+	c.log.Info("Logged in to Peplink API", "status", rr.Status(), "username", c.username)
+
+	// The session ID is tracked as the token to detect the rejected session in reauthenticate
+	c.setToken(session)
+
+	return nil
+}
+
+// logout ends the admin session on the device
+func (c *Client) logout(ctx context.Context) error {
+	_, err := c.doRequestWithToken(ctx, postRequest("/api/logout", nil), c.accessToken())
+	if err != nil {
+		return fmt.Errorf("failed to logout: %w", err)
+	}
+
+	return nil
+}
+
+// renewAuth obtains new credentials with the configured auth mode
+func (c *Client) renewAuth(ctx context.Context) error {
+	switch c.authMode {
+	case authModeSession:
+		return c.login(ctx)
+	default:
+		_, err := c.authenticate(ctx, c.clientID, c.clientSecret)
+		return err
+	}
+}
+
+// canReauthenticate reports whether the Client has credentials to renew the rejected token or session
+func (c *Client) canReauthenticate() bool {
+	switch c.authMode {
+	case authModeSession:
+		return c.username != ""
+	default:
+		return c.clientID != ""
+	}
+}
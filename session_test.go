@@ -0,0 +1,92 @@
+package peplink
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_adminLogin(t *testing.T) {
+	var (
+		mu      sync.Mutex
+		logins  int
+		session string
+	)
+	srv := httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			mu.Lock()
+			defer mu.Unlock()
+
+			switch r.URL.Path {
+			case "/api/login":
+				creds := map[string]string{}
+				require.NoError(t, json.NewDecoder(r.Body).Decode(&creds))
+				if creds["username"] != "admin" || creds["password"] != "secret" {
+					w.Write([]byte(`{"stat": "fail", "code": 401, "message": "Unauthorized"}`))
+					return
+				}
+				logins++
+				session = fmt.Sprintf("session-%d", logins)
+				http.SetCookie(w, &http.Cookie{Name: "bauth", Value: session, Path: "/"})
+				w.Write([]byte(`{"stat": "ok"}`))
+			case "/api/info.frw.version":
+				require.Empty(t, r.URL.Query().Get("accessToken"))
+				ck, err := r.Cookie("bauth")
+				if err != nil || ck.Value != session {
+					w.WriteHeader(http.StatusUnauthorized)
+					w.Write([]byte(`{"stat": "fail", "code": 401, "message": "Unauthorized"}`))
+					return
+				}
+				w.Write([]byte(`{"stat": "ok", "response": {"1": {"version": "8.3.0 build 5229", "bootable": true, "inUse": true}, "order": [1]}}`))
+			case "/api/logout":
+				session = ""
+				w.Write([]byte(`{"stat": "ok"}`))
+			default:
+				t.Errorf("unexpected path: %s", r.URL.Path)
+			}
+		}),
+	)
+	defer srv.Close()
+
+	ctx := context.Background()
+
+	t.Run("wrong password", func(t *testing.T) {
+		_, err := NewClient(ctx, WithHTTPBasicURL(srv.URL), WithAdminLogin("admin", "wrong"))
+		require.ErrorIs(t, err, ErrUnauthorized)
+	})
+
+	t.Run("session expiry", func(t *testing.T) {
+		c, err := NewClient(ctx, WithHTTPBasicURL(srv.URL), WithAdminLogin("admin", "secret"), WithRevokeTokenOnClose())
+		require.NoError(t, err)
+		require.False(t, c.TokenRefreshStatus().Running, "sessions don't need the token refresher")
+
+		got, err := c.FirmwareVersion(ctx)
+		require.NoError(t, err)
+		require.Equal(t, "8.3.0 build 5229", got)
+
+		// Device drops the session
+		mu.Lock()
+		session = "expired"
+		mu.Unlock()
+
+		got, err = c.FirmwareVersion(ctx)
+		require.NoError(t, err)
+		require.Equal(t, "8.3.0 build 5229", got)
+
+		mu.Lock()
+		require.Equal(t, 2, logins, "expired session must be renewed by the login")
+		mu.Unlock()
+
+		require.NoError(t, c.Close(ctx))
+		mu.Lock()
+		require.Empty(t, session, "Close must logout")
+		mu.Unlock()
+	})
+}
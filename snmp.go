@@ -0,0 +1,359 @@
+package peplink
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gosnmp/gosnmp"
+)
+
+// OIDs from the Peplink balance MIB
+const (
+	oidDeviceSerialNumber    = ".1.3.6.1.4.1.23695.200.1.1.1.1.1.0"
+	oidDeviceFirmwareVersion = ".1.3.6.1.4.1.23695.200.1.1.1.1.2.0"
+
+	// wanStatusEntry. Rows are indexed by the WAN connection ID
+	oidWanStatusEntry = ".1.3.6.1.4.1.23695.200.1.10.1.1.1.1"
+)
+
+// OIDs from the SNMPv2-MIB and IF-MIB
+const (
+	oidSysDescr  = ".1.3.6.1.2.1.1.1.0" // Model name on Peplink devices
+	oidSysUpTime = ".1.3.6.1.2.1.1.3.0" // TimeTicks. In hundredths of a second
+	oidSysName   = ".1.3.6.1.2.1.1.5.0"
+
+	// ifPhysAddress column of the ifTable. Rows are indexed by the interface
+	oidIfPhysAddress = ".1.3.6.1.2.1.2.2.1.6"
+)
+
+// Columns of the wanStatusEntry
+const (
+	wanColName     = 2 // wanName DisplayString
+	wanColStatus   = 3 // wanStatus DisplayString. Same as the message of the HTTP API
+	wanColPriority = 4 // wanPriority Integer. 0 if the WAN is disabled
+	wanColIP       = 5 // wanIp IpAddress
+	wanColType     = 6 // wanType DisplayString { modem, wireless, gobi, cellular, ipsec, adsl, ethernet }
+	wanColUptime   = 7 // wanUptime Integer. In seconds
+)
+
+// SNMPv3Credentials configures the user-based security model for SNMPv3
+type SNMPv3Credentials struct {
+	Username string
+	// Authentication protocol { MD5, SHA, SHA224, SHA256, SHA384, SHA512 }. Empty means no authentication
+	AuthProtocol   string
+	AuthPassphrase string
+	// Privacy protocol { DES, AES, AES192, AES256 }. Empty means no privacy
+	PrivProtocol   string
+	PrivPassphrase string
+}
+
+type snmpConfig struct {
+	address   string
+	community string
+	timeout   time.Duration
+	v3        *SNMPv3Credentials
+}
+
+// SerialNumberSNMP returns the serial number of the device queried via SNMP
+func (c *Client) SerialNumberSNMP(ctx context.Context) (string, error) {
+	pdu, err := c.snmpGet(ctx, oidDeviceSerialNumber)
+	if err != nil {
+		return "", fmt.Errorf("failed to get serial number via snmp: %w", err)
+	}
+
+	return snmpString(pdu), nil
+}
+
+// FirmwareVersionSNMP returns the firmware version of the device queried via SNMP
+func (c *Client) FirmwareVersionSNMP(ctx context.Context) (string, error) {
+	pdu, err := c.snmpGet(ctx, oidDeviceFirmwareVersion)
+	if err != nil {
+		return "", fmt.Errorf("failed to get firmware version via snmp: %w", err)
+	}
+
+	return snmpString(pdu), nil
+}
+
+// StatusWanConnectionSNMP returns the status of the WAN connections queried via SNMP
+// Only the fields exposed by the MIB are filled
+func (c *Client) StatusWanConnectionSNMP(ctx context.Context) ([]WanStatus, error) {
+	s, err := c.snmpSession(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get wan status via snmp: %w", err)
+	}
+	defer s.Conn.Close()
+
+	pdus, err := s.BulkWalkAll(oidWanStatusEntry)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get wan status via snmp: %w", err)
+	}
+
+	order := []int{}
+	wans := map[int]*WanStatus{}
+
+	for _, pdu := range pdus {
+		column, id, err := snmpTableIndex(oidWanStatusEntry, pdu.Name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get wan status via snmp: %w", err)
+		}
+		wan, ok := wans[id]
+		if !ok {
+			wan = &WanStatus{ID: id}
+			wans[id] = wan
+			order = append(order, id)
+		}
+
+		switch column {
+		case wanColName:
+			wan.Name = snmpString(pdu)
+		case wanColStatus:
+			wan.Message = snmpString(pdu)
+		case wanColPriority:
+			if priority := snmpInt(pdu); priority > 0 {
+				wan.Priority = &priority
+			}
+			wan.Enable = wan.Priority != nil
+		case wanColIP:
+			wan.Ip = snmpString(pdu)
+		case wanColType:
+			wan.Type = snmpString(pdu)
+			wan.VirtualType = wan.Type
+		case wanColUptime:
+			wan.Uptime = snmpInt(pdu)
+		}
+	}
+
+	sort.Ints(order)
+	statuses := []WanStatus{}
+	for _, id := range order {
+		statuses = append(statuses, *wans[id])
+	}
+
+	return statuses, nil
+}
+
+// DeviceInfoSNMP returns the identity of the device queried via SNMP
+// ProductCode and HardwareRevision are not exposed by the MIB and stay empty
+func (c *Client) DeviceInfoSNMP(ctx context.Context) (DeviceInfo, error) {
+	s, err := c.snmpSession(ctx)
+	if err != nil {
+		return DeviceInfo{}, fmt.Errorf("failed to get device info via snmp: %w", err)
+	}
+	defer s.Conn.Close()
+
+	pkt, err := s.Get([]string{oidDeviceSerialNumber, oidSysDescr, oidSysName, oidSysUpTime})
+	if err != nil {
+		return DeviceInfo{}, fmt.Errorf("failed to get device info via snmp: %w", err)
+	}
+	if pkt.Error != gosnmp.NoError {
+		return DeviceInfo{}, fmt.Errorf("failed to get device info via snmp: snmp error: %s", pkt.Error)
+	}
+
+	info := DeviceInfo{}
+	for _, pdu := range pkt.Variables {
+		switch pdu.Type {
+		case gosnmp.NoSuchObject, gosnmp.NoSuchInstance, gosnmp.EndOfMibView, gosnmp.Null:
+			continue
+		}
+		switch pdu.Name {
+		case oidDeviceSerialNumber:
+			info.SerialNumber = snmpString(pdu)
+		case oidSysDescr:
+			info.Model = snmpString(pdu)
+		case oidSysName:
+			info.Hostname = snmpString(pdu)
+		case oidSysUpTime:
+			info.Uptime = snmpInt(pdu) / 100
+		}
+	}
+	if info.SerialNumber == "" {
+		return DeviceInfo{}, fmt.Errorf("failed to get device info via snmp: oid %s is not available", oidDeviceSerialNumber)
+	}
+
+	pdus, err := s.BulkWalkAll(oidIfPhysAddress)
+	if err != nil {
+		return DeviceInfo{}, fmt.Errorf("failed to get device info via snmp: %w", err)
+	}
+	for _, pdu := range pdus {
+		mac, ok := pdu.Value.([]byte)
+		if !ok || len(mac) == 0 {
+			continue
+		}
+		info.MACAddresses = append(info.MACAddresses, strings.ToUpper(net.HardwareAddr(mac).String()))
+	}
+
+	return info, nil
+}
+
+func (c *Client) snmpGet(ctx context.Context, oid string) (gosnmp.SnmpPDU, error) {
+	s, err := c.snmpSession(ctx)
+	if err != nil {
+		return gosnmp.SnmpPDU{}, err
+	}
+	defer s.Conn.Close()
+
+	pkt, err := s.Get([]string{oid})
+	if err != nil {
+		return gosnmp.SnmpPDU{}, fmt.Errorf("failed to do SNMP request: %w", err)
+	}
+	if pkt.Error != gosnmp.NoError {
+		return gosnmp.SnmpPDU{}, fmt.Errorf("snmp error: %s", pkt.Error)
+	}
+	if len(pkt.Variables) != 1 {
+		return gosnmp.SnmpPDU{}, fmt.Errorf("unexpected number of variables in response: %d", len(pkt.Variables))
+	}
+
+	pdu := pkt.Variables[0]
+	switch pdu.Type {
+	case gosnmp.NoSuchObject, gosnmp.NoSuchInstance, gosnmp.EndOfMibView, gosnmp.Null:
+		return gosnmp.SnmpPDU{}, fmt.Errorf("oid %s is not available: %s", oid, pdu.Type)
+	}
+
+	return pdu, nil
+}
+
+// snmpSession creates a connected SNMP session. Caller must close the connection
+func (c *Client) snmpSession(ctx context.Context) (*gosnmp.GoSNMP, error) {
+	if c.closed.Load() {
+		return nil, ErrClientClosed
+	}
+
+	host, portStr, err := net.SplitHostPort(c.snmp.address)
+	if err != nil {
+		return nil, fmt.Errorf("invalid snmp address '%s': %w", c.snmp.address, err)
+	}
+	port, err := strconv.ParseUint(portStr, 10, 16)
+	if err != nil {
+		return nil, fmt.Errorf("invalid snmp port '%s': %w", portStr, err)
+	}
+
+	s := &gosnmp.GoSNMP{
+		Context:   ctx,
+		Target:    host,
+		Port:      uint16(port),
+		Transport: "udp",
+		Community: c.snmp.community,
+		Version:   gosnmp.Version2c,
+		Timeout:   c.snmp.timeout,
+		Retries:   1,
+		MaxOids:   gosnmp.MaxOids,
+	}
+
+	if c.snmp.v3 != nil {
+		err = applySNMPv3(s, c.snmp.v3)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err = s.Connect()
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to %s: %w", c.snmp.address, err)
+	}
+
+	return s, nil
+}
+
+func applySNMPv3(s *gosnmp.GoSNMP, creds *SNMPv3Credentials) error {
+	params := &gosnmp.UsmSecurityParameters{
+		UserName:                 creds.Username,
+		AuthenticationProtocol:   gosnmp.NoAuth,
+		PrivacyProtocol:          gosnmp.NoPriv,
+		AuthenticationPassphrase: creds.AuthPassphrase,
+		PrivacyPassphrase:        creds.PrivPassphrase,
+	}
+
+	switch strings.ToUpper(creds.AuthProtocol) {
+	case "":
+	case "MD5":
+		params.AuthenticationProtocol = gosnmp.MD5
+	case "SHA":
+		params.AuthenticationProtocol = gosnmp.SHA
+	case "SHA224":
+		params.AuthenticationProtocol = gosnmp.SHA224
+	case "SHA256":
+		params.AuthenticationProtocol = gosnmp.SHA256
+	case "SHA384":
+		params.AuthenticationProtocol = gosnmp.SHA384
+	case "SHA512":
+		params.AuthenticationProtocol = gosnmp.SHA512
+	default:
+		return fmt.Errorf("unknown snmpv3 auth protocol: %s", creds.AuthProtocol)
+	}
+
+	switch strings.ToUpper(creds.PrivProtocol) {
+	case "":
+	case "DES":
+		params.PrivacyProtocol = gosnmp.DES
+	case "AES":
+		params.PrivacyProtocol = gosnmp.AES
+	case "AES192":
+		params.PrivacyProtocol = gosnmp.AES192
+	case "AES256":
+		params.PrivacyProtocol = gosnmp.AES256
+	default:
+		return fmt.Errorf("unknown snmpv3 privacy protocol: %s", creds.PrivProtocol)
+	}
+
+	flags := gosnmp.NoAuthNoPriv
+	if params.AuthenticationProtocol != gosnmp.NoAuth {
+		flags = gosnmp.AuthNoPriv
+	}
+	if params.PrivacyProtocol != gosnmp.NoPriv {
+		if flags == gosnmp.NoAuthNoPriv {
+			return fmt.Errorf("snmpv3 privacy requires an auth protocol")
+		}
+		flags = gosnmp.AuthPriv
+	}
+
+	s.Version = gosnmp.Version3
+	s.SecurityModel = gosnmp.UserSecurityModel
+	s.MsgFlags = flags
+	s.SecurityParameters = params
+
+	return nil
+}
+
+// snmpTableIndex splits the OID of the table cell into the column and row index
+func snmpTableIndex(entry, oid string) (int, int, error) {
+	rest, ok := strings.CutPrefix(oid, entry+".")
+	if !ok {
+		return 0, 0, fmt.Errorf("oid %s is not under %s", oid, entry)
+	}
+	column, index, ok := strings.Cut(rest, ".")
+	if !ok {
+		return 0, 0, fmt.Errorf("oid %s has no row index", oid)
+	}
+	col, err := strconv.Atoi(column)
+	if err != nil {
+		return 0, 0, fmt.Errorf("oid %s has invalid column: %w", oid, err)
+	}
+	id, err := strconv.Atoi(index)
+	if err != nil {
+		return 0, 0, fmt.Errorf("oid %s has invalid row index: %w", oid, err)
+	}
+
+	return col, id, nil
+}
+
+func snmpString(pdu gosnmp.SnmpPDU) string {
+	switch v := pdu.Value.(type) {
+	case []byte:
+		return string(v)
+	case string:
+		return v
+	case nil:
+		return ""
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+func snmpInt(pdu gosnmp.SnmpPDU) int {
+	return int(gosnmp.ToBigInt(pdu.Value).Int64())
+}
@@ -0,0 +1,223 @@
+package peplink
+
+import (
+	"context"
+	"log/slog"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gosnmp/gosnmp"
+	"github.com/stretchr/testify/require"
+)
+
+// snmpAgent is a minimal SNMPv2c agent stand-in. Serves Get, GetNext and GetBulk from the static set of PDUs
+type snmpAgent struct {
+	conn      *net.UDPConn
+	community string
+	oids      []string
+	values    map[string]gosnmp.SnmpPDU
+}
+
+func newSNMPAgent(t *testing.T, community string, pdus []gosnmp.SnmpPDU) string {
+	t.Helper()
+
+	conn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	a := &snmpAgent{conn: conn, community: community, values: map[string]gosnmp.SnmpPDU{}}
+	for _, pdu := range pdus {
+		a.oids = append(a.oids, pdu.Name)
+		a.values[pdu.Name] = pdu
+	}
+	sort.Slice(a.oids, func(i, j int) bool { return oidLess(a.oids[i], a.oids[j]) })
+
+	go a.serve()
+
+	return conn.LocalAddr().String()
+}
+
+func (a *snmpAgent) serve() {
+	buf := make([]byte, 65535)
+	decoder := &gosnmp.GoSNMP{Version: gosnmp.Version2c, Community: a.community}
+	for {
+		n, addr, err := a.conn.ReadFromUDP(buf)
+		if err != nil {
+			return
+		}
+		req, err := decoder.SnmpDecodePacket(buf[:n])
+		if err != nil || req.Community != a.community {
+			continue
+		}
+
+		var vars []gosnmp.SnmpPDU
+		switch req.PDUType {
+		case gosnmp.GetRequest:
+			for _, v := range req.Variables {
+				pdu, ok := a.values[v.Name]
+				if !ok {
+					pdu = gosnmp.SnmpPDU{Name: v.Name, Type: gosnmp.NoSuchObject}
+				}
+				vars = append(vars, pdu)
+			}
+		case gosnmp.GetNextRequest:
+			for _, v := range req.Variables {
+				vars = append(vars, a.next(v.Name))
+			}
+		case gosnmp.GetBulkRequest:
+			oid := req.Variables[0].Name
+			for i := 0; i < int(req.MaxRepetitions); i++ {
+				pdu := a.next(oid)
+				vars = append(vars, pdu)
+				if pdu.Type == gosnmp.EndOfMibView {
+					break
+				}
+				oid = pdu.Name
+			}
+		default:
+			continue
+		}
+
+		req.PDUType = gosnmp.GetResponse
+		req.Variables = vars
+		out, err := req.MarshalMsg()
+		if err != nil {
+			continue
+		}
+		a.conn.WriteToUDP(out, addr)
+	}
+}
+
+func (a *snmpAgent) next(oid string) gosnmp.SnmpPDU {
+	i := sort.Search(len(a.oids), func(i int) bool { return oidLess(oid, a.oids[i]) })
+	if i == len(a.oids) {
+		return gosnmp.SnmpPDU{Name: oid, Type: gosnmp.EndOfMibView}
+	}
+
+	return a.values[a.oids[i]]
+}
+
+func oidLess(a, b string) bool {
+	as := strings.Split(strings.TrimPrefix(a, "."), ".")
+	bs := strings.Split(strings.TrimPrefix(b, "."), ".")
+	for i := 0; i < len(as) && i < len(bs); i++ {
+		x, _ := strconv.Atoi(as[i])
+		y, _ := strconv.Atoi(bs[i])
+		if x != y {
+			return x < y
+		}
+	}
+
+	return len(as) < len(bs)
+}
+
+func wanPDU(column, id int, t gosnmp.Asn1BER, value interface{}) gosnmp.SnmpPDU {
+	return gosnmp.SnmpPDU{
+		Name:  oidWanStatusEntry + "." + strconv.Itoa(column) + "." + strconv.Itoa(id),
+		Type:  t,
+		Value: value,
+	}
+}
+
+func newSNMPTestClient(addr, community string) *Client {
+	return &Client{
+		snmp: snmpConfig{
+			address:   addr,
+			community: community,
+			timeout:   time.Second,
+		},
+		log: slog.Default(),
+	}
+}
+
+func TestClient_SNMP(t *testing.T) {
+	addr := newSNMPAgent(t, "public", []gosnmp.SnmpPDU{
+		{Name: oidDeviceSerialNumber, Type: gosnmp.OctetString, Value: []byte("1111-2222-3333")},
+		{Name: oidDeviceFirmwareVersion, Type: gosnmp.OctetString, Value: []byte("8.3.0 build 5229")},
+		wanPDU(wanColName, 1, gosnmp.OctetString, []byte("WAN 1")),
+		wanPDU(wanColName, 3, gosnmp.OctetString, []byte("Cellular 1")),
+		wanPDU(wanColStatus, 1, gosnmp.OctetString, []byte("No Cable Detected")),
+		wanPDU(wanColStatus, 3, gosnmp.OctetString, []byte("Connected to Carrier1")),
+		wanPDU(wanColPriority, 1, gosnmp.Integer, 1),
+		wanPDU(wanColPriority, 3, gosnmp.Integer, 2),
+		wanPDU(wanColIP, 3, gosnmp.IPAddress, "10.10.10.10"),
+		wanPDU(wanColType, 1, gosnmp.OctetString, []byte("ethernet")),
+		wanPDU(wanColType, 3, gosnmp.OctetString, []byte("cellular")),
+		wanPDU(wanColUptime, 1, gosnmp.Integer, 0),
+		wanPDU(wanColUptime, 3, gosnmp.Integer, 3314261),
+		// Must not leak into the WAN table
+		{Name: ".1.3.6.1.4.1.23695.200.1.10.2.1.0", Type: gosnmp.Integer, Value: 1},
+		{Name: oidSysDescr, Type: gosnmp.OctetString, Value: []byte("MAX BR1 Mini")},
+		{Name: oidSysUpTime, Type: gosnmp.TimeTicks, Value: uint32(331426100)},
+		{Name: oidSysName, Type: gosnmp.OctetString, Value: []byte("site-a")},
+		{Name: oidIfPhysAddress + ".1", Type: gosnmp.OctetString, Value: []byte{}},
+		{Name: oidIfPhysAddress + ".2", Type: gosnmp.OctetString, Value: []byte{0x00, 0x1a, 0xdd, 0x00, 0x00, 0x01}},
+		{Name: oidIfPhysAddress + ".3", Type: gosnmp.OctetString, Value: []byte{0x00, 0x1a, 0xdd, 0x00, 0x00, 0x02}},
+	})
+
+	t.Run("serial", func(t *testing.T) {
+		c := newSNMPTestClient(addr, "public")
+		got, err := c.SerialNumberSNMP(context.Background())
+		require.NoError(t, err)
+		require.Equal(t, "1111-2222-3333", got)
+	})
+
+	t.Run("firmware", func(t *testing.T) {
+		c := newSNMPTestClient(addr, "public")
+		got, err := c.FirmwareVersionSNMP(context.Background())
+		require.NoError(t, err)
+		require.Equal(t, "8.3.0 build 5229", got)
+	})
+
+	t.Run("wan", func(t *testing.T) {
+		c := newSNMPTestClient(addr, "public")
+		got, err := c.StatusWanConnectionSNMP(context.Background())
+		require.NoError(t, err)
+		require.Equal(t, []WanStatus{
+			{
+				ID:          1,
+				Name:        "WAN 1",
+				Enable:      true,
+				Message:     "No Cable Detected",
+				Type:        "ethernet",
+				VirtualType: "ethernet",
+				Priority:    ptr(1),
+			},
+			{
+				ID:          3,
+				Name:        "Cellular 1",
+				Enable:      true,
+				Message:     "Connected to Carrier1",
+				Uptime:      3314261,
+				Type:        "cellular",
+				VirtualType: "cellular",
+				Priority:    ptr(2),
+				Ip:          "10.10.10.10",
+			},
+		}, got)
+	})
+
+	t.Run("device info", func(t *testing.T) {
+		c := newSNMPTestClient(addr, "public")
+		got, err := c.DeviceInfoSNMP(context.Background())
+		require.NoError(t, err)
+		require.Equal(t, DeviceInfo{
+			SerialNumber: "1111-2222-3333",
+			Model:        "MAX BR1 Mini",
+			Hostname:     "site-a",
+			Uptime:       3314261,
+			MACAddresses: []string{"00:1A:DD:00:00:01", "00:1A:DD:00:00:02"},
+		}, got)
+	})
+
+	t.Run("wrong community", func(t *testing.T) {
+		c := newSNMPTestClient(addr, "private")
+		c.snmp.timeout = 100 * time.Millisecond
+		_, err := c.SerialNumberSNMP(context.Background())
+		require.Error(t, err)
+	})
+}
@@ -0,0 +1,103 @@
+package peplink
+
+import (
+	"context"
+	"fmt"
+
+	"golang.org/x/time/rate"
+)
+
+// Throttle limits the load the Client puts on the device
+// Token grants and logins are exempt by default, so the authentication never starves behind the queued requests
+type Throttle struct {
+	RequestsPerSecond float64 // Rate of the token bucket. 0 disables the rate limit
+	Burst             int     // Size of the token bucket. Values below 1 mean 1
+	MaxInFlight       int     // Maximum of the concurrent requests. 0 means unlimited
+	// ThrottleAuth applies the rate limit to the token grants and logins too
+	// They never wait for MaxInFlight because re-authentication happens while the request slot is held
+	ThrottleAuth bool
+}
+
+type throttle struct {
+	limiter *rate.Limiter // nil if the rate isn't limited
+	slots   chan struct{} // nil if the concurrency isn't limited
+	auth    bool
+}
+
+func newThrottle(t Throttle) *throttle {
+	if t.RequestsPerSecond <= 0 && t.MaxInFlight <= 0 {
+		return nil
+	}
+
+	th := &throttle{auth: t.ThrottleAuth}
+	if t.RequestsPerSecond > 0 {
+		burst := t.Burst
+		if burst < 1 {
+			burst = 1
+		}
+		th.limiter = rate.NewLimiter(rate.Limit(t.RequestsPerSecond), burst)
+	}
+	if t.MaxInFlight > 0 {
+		th.slots = make(chan struct{}, t.MaxInFlight)
+	}
+
+	return th
+}
+
+// acquire waits for the request slot and the rate limit. The returned func releases the slot
+func (t *throttle) acquire(ctx context.Context) (func(), error) {
+	if t == nil {
+		return func() {}, nil
+	}
+
+	release := func() {}
+	if t.slots != nil {
+		select {
+		case t.slots <- struct{}{}:
+			release = func() { <-t.slots }
+		case <-ctx.Done():
+			return nil, &throttleError{err: fmt.Errorf("failed to wait for the request slot: %w", ctx.Err())}
+		}
+	}
+
+	err := t.wait(ctx)
+	if err != nil {
+		release()
+		return nil, err
+	}
+
+	return release, nil
+}
+
+// acquireAuth waits for the rate limit of the authentication requests if ThrottleAuth is set
+func (t *throttle) acquireAuth(ctx context.Context) error {
+	if t == nil || !t.auth {
+		return nil
+	}
+
+	return t.wait(ctx)
+}
+
+// wait waits for the rate limit only. The request slot isn't touched
+func (t *throttle) wait(ctx context.Context) error {
+	if t == nil || t.limiter == nil {
+		return nil
+	}
+
+	err := t.limiter.Wait(ctx)
+	if err != nil {
+		return &throttleError{err: fmt.Errorf("failed to wait for the rate limit: %w", err)}
+	}
+
+	return nil
+}
+
+// throttleError is returned when the request gives up waiting for the throttle
+// It isn't retried: the limiter fails early if the wait won't fit the deadline, so the next attempt fails the same way
+type throttleError struct {
+	err error
+}
+
+func (e *throttleError) Error() string { return e.err.Error() }
+
+func (e *throttleError) Unwrap() error { return e.err }
@@ -0,0 +1,158 @@
+package peplink
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func newThrottleTestClient(t *testing.T, th Throttle, handler http.HandlerFunc) *Client {
+	t.Helper()
+
+	c := newTestClient(t, handler)
+	c.clientID = "client_id"
+	c.clientSecret = "client_secret"
+	c.token = "token"
+	c.throttle = newThrottle(th)
+
+	return c
+}
+
+func TestClient_throttleMaxInFlight(t *testing.T) {
+	var inFlight, maxInFlight atomic.Int32
+	c := newThrottleTestClient(t, Throttle{MaxInFlight: 2}, func(w http.ResponseWriter, r *http.Request) {
+		n := inFlight.Add(1)
+		defer inFlight.Add(-1)
+		for {
+			m := maxInFlight.Load()
+			if n <= m || maxInFlight.CompareAndSwap(m, n) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"stat": "ok", "response": {}}`))
+	})
+
+	errs := make([]error, 8)
+	var wg sync.WaitGroup
+	for i := range errs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, errs[i] = c.doRequest(context.Background(), getRequest("/api/status.wan.connection"))
+		}(i)
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		require.NoError(t, err)
+	}
+
+	require.Equal(t, int32(2), maxInFlight.Load())
+}
+
+func TestClient_throttleRate(t *testing.T) {
+	c := newThrottleTestClient(t, Throttle{RequestsPerSecond: 20, Burst: 1}, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"stat": "ok", "response": {}}`))
+	})
+
+	start := time.Now()
+	for i := 0; i < 5; i++ {
+		_, err := c.doRequest(context.Background(), getRequest("/api/status.wan.connection"))
+		require.NoError(t, err)
+	}
+	require.GreaterOrEqual(t, time.Since(start), 190*time.Millisecond, "5 requests at 20 rps must take at least 200ms")
+}
+
+func TestClient_throttleDeadline(t *testing.T) {
+	unblock := make(chan struct{})
+	c := newThrottleTestClient(t, Throttle{MaxInFlight: 1}, func(w http.ResponseWriter, r *http.Request) {
+		<-unblock
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"stat": "ok", "response": {}}`))
+	})
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		c.doRequest(context.Background(), getRequest("/api/status.wan.connection"))
+	}()
+	require.Eventually(t, func() bool { return len(c.throttle.slots) == 1 }, time.Second, time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, err := c.doRequest(ctx, getRequest("/api/status.wan.connection"))
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+
+	close(unblock)
+	<-done
+}
+
+func TestClient_throttleNotRetried(t *testing.T) {
+	var requests atomic.Int32
+	c := newThrottleTestClient(t, Throttle{RequestsPerSecond: 1, Burst: 1}, func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"stat": "ok", "response": {}}`))
+	})
+	c.retry = RetryPolicy{MaxAttempts: 3, MinBackoff: time.Millisecond, MaxBackoff: time.Millisecond}
+
+	_, err := c.doRequest(context.Background(), getRequest("/api/status.wan.connection"))
+	require.NoError(t, err)
+
+	// The next token is a second away, so the limiter fails before the deadline
+	ctx, cancel := context.WithTimeout(context.Background(), 300*time.Millisecond)
+	defer cancel()
+	_, err = c.doRequest(ctx, getRequest("/api/status.wan.connection"))
+	require.ErrorContains(t, err, "would exceed context deadline")
+	require.NoError(t, ctx.Err(), "the deadline isn't reached yet")
+
+	_, retry := c.retryDelay(ctx, methodGet, 1, err)
+	require.False(t, retry, "throttle errors must not be retried")
+	require.Equal(t, int32(1), requests.Load())
+}
+
+func TestClient_throttleAuthExempt(t *testing.T) {
+	tests := []struct {
+		name        string
+		throttle    Throttle
+		minDuration time.Duration
+	}{
+		{"exempt by default", Throttle{RequestsPerSecond: 2, Burst: 1, MaxInFlight: 1}, 400 * time.Millisecond},
+		{"throttled auth", Throttle{RequestsPerSecond: 2, Burst: 1, MaxInFlight: 1, ThrottleAuth: true}, 900 * time.Millisecond},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newThrottleTestClient(t, tt.throttle, func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				switch r.URL.Path {
+				case "/api/auth.token.grant":
+					w.Write([]byte(`{"stat": "ok", "response": {"accessToken": "fresh", "expiresIn": "172800"}}`))
+				default:
+					if r.URL.Query().Get("accessToken") != "fresh" {
+						w.Write([]byte(`{"stat": "fail", "code": 401, "message": "Unauthorized"}`))
+						return
+					}
+					w.Write([]byte(`{"stat": "ok", "response": {}}`))
+				}
+			})
+
+			// The rate limit is spent by the request which gets the token rejected
+			// The replay waits for the rate limit again, the throttled grant waits before it
+			start := time.Now()
+			_, err := c.doRequest(context.Background(), getRequest("/api/status.wan.connection"))
+			require.NoError(t, err)
+			require.GreaterOrEqual(t, time.Since(start), tt.minDuration)
+			if !tt.throttle.ThrottleAuth {
+				require.Less(t, time.Since(start), 800*time.Millisecond, "token grant must not wait for the rate limit")
+			}
+		})
+	}
+}
@@ -0,0 +1,69 @@
+package peplink
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+type tlsOptions struct {
+	rootCAs            *x509.CertPool
+	pins               [][]byte // SHA-256 fingerprints of the accepted leaf certificates
+	insecureSkipVerify bool
+	clientCertificates []tls.Certificate
+}
+
+// config builds the TLS configuration. Returns nil if no TLS option is set
+func (o tlsOptions) config() *tls.Config {
+	if o.rootCAs == nil && len(o.pins) == 0 && !o.insecureSkipVerify && len(o.clientCertificates) == 0 {
+		return nil
+	}
+
+	cfg := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		RootCAs:            o.rootCAs,
+		Certificates:       o.clientCertificates,
+		InsecureSkipVerify: o.insecureSkipVerify,
+	}
+
+	// Pinning replaces the chain verification: devices use self-signed certificates
+	if len(o.pins) > 0 {
+		cfg.InsecureSkipVerify = true
+		cfg.VerifyConnection = o.verifyPin
+	}
+
+	return cfg
+}
+
+func (o tlsOptions) verifyPin(cs tls.ConnectionState) error {
+	if len(cs.PeerCertificates) == 0 {
+		return fmt.Errorf("no peer certificate to verify the pin")
+	}
+
+	sum := sha256.Sum256(cs.PeerCertificates[0].Raw)
+	for _, pin := range o.pins {
+		if bytes.Equal(pin, sum[:]) {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("certificate fingerprint %s doesn't match any pinned fingerprint", hex.EncodeToString(sum[:]))
+}
+
+// parseFingerprint decodes the SHA-256 fingerprint in hex. Colons and spaces are ignored
+func parseFingerprint(fp string) ([]byte, error) {
+	clean := strings.NewReplacer(":", "", " ", "").Replace(fp)
+	pin, err := hex.DecodeString(clean)
+	if err != nil {
+		return nil, fmt.Errorf("fingerprint '%s' isn't hex: %w", fp, err)
+	}
+	if len(pin) != sha256.Size {
+		return nil, fmt.Errorf("fingerprint '%s' must be %d bytes, got %d", fp, sha256.Size, len(pin))
+	}
+
+	return pin, nil
+}
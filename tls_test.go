@@ -0,0 +1,127 @@
+package peplink
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/hex"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func tokenGrantHandler(t *testing.T) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/api/auth.token.grant", r.URL.Path)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"stat": "ok", "response": {"accessToken": "token", "expiresIn": "172800"}}`))
+	}
+}
+
+func TestNewClient_TLS(t *testing.T) {
+	srv := httptest.NewTLSServer(tokenGrantHandler(t))
+	defer srv.Close()
+
+	pool := x509.NewCertPool()
+	pool.AddCert(srv.Certificate())
+
+	sum := sha256.Sum256(srv.Certificate().Raw)
+	pin := hex.EncodeToString(sum[:])
+	colonPin := strings.ToUpper(strings.Join(splitEvery(pin, 2), ":"))
+
+	tests := []struct {
+		name    string
+		opts    []Option
+		wantErr bool
+	}{
+		{"system roots reject self-signed", nil, true},
+		{"custom CA pool", []Option{WithTLSRootCAs(pool)}, false},
+		{"pinned fingerprint", []Option{WithTLSPinnedSHA256(pin)}, false},
+		{"pinned fingerprint with colons", []Option{WithTLSPinnedSHA256(strings.Repeat("00", 32), colonPin)}, false},
+		{"wrong pin", []Option{WithTLSPinnedSHA256(strings.Repeat("ab", 32))}, true},
+		{"insecure skip verify", []Option{WithTLSInsecureSkipVerify()}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := append([]Option{
+				WithHTTPBasicURL(srv.URL),
+				WithHTTPBasicClientID("client_id"),
+				WithHTTPBasicClientSecret("client_secret"),
+			}, tt.opts...)
+
+			c, err := NewClient(context.Background(), opts...)
+			require.Equal(t, tt.wantErr, err != nil, "NewClient() error = %v, wantErr %v", err, tt.wantErr)
+			if c != nil {
+				c.Close(context.Background())
+			}
+		})
+	}
+}
+
+func TestNewClient_TLSClientCertificate(t *testing.T) {
+	srv := httptest.NewUnstartedServer(tokenGrantHandler(t))
+	srv.TLS = &tls.Config{ClientAuth: tls.RequireAnyClientCert}
+	srv.StartTLS()
+	defer srv.Close()
+
+	pool := x509.NewCertPool()
+	pool.AddCert(srv.Certificate())
+	opts := []Option{
+		WithHTTPBasicURL(srv.URL),
+		WithHTTPBasicClientID("client_id"),
+		WithHTTPBasicClientSecret("client_secret"),
+		WithTLSRootCAs(pool),
+	}
+
+	_, err := NewClient(context.Background(), opts...)
+	require.Error(t, err, "server must require the client certificate")
+
+	c, err := NewClient(context.Background(), append(opts, WithTLSClientCertificate(selfSignedCertificate(t)))...)
+	require.NoError(t, err)
+	c.Close(context.Background())
+}
+
+func TestWithTLSPinnedSHA256_invalid(t *testing.T) {
+	for _, fp := range []string{"", "zz", strings.Repeat("ab", 20)} {
+		err := WithTLSPinnedSHA256(fp)(&options{})
+		require.ErrorIs(t, err, ErrInvalidOption, "fingerprint %q", fp)
+	}
+}
+
+func selfSignedCertificate(t *testing.T) tls.Certificate {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "peplink-client"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	require.NoError(t, err)
+
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}
+}
+
+func splitEvery(s string, n int) []string {
+	var parts []string
+	for len(s) > n {
+		parts = append(parts, s[:n])
+		s = s[n:]
+	}
+
+	return append(parts, s)
+}
@@ -0,0 +1,156 @@
+package peplink
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// tokenRefreshMargin is how long before the expiration the token is refreshed
+// TTLs shorter than 5*tokenRefreshMargin are refreshed after 80% of the TTL instead
+const tokenRefreshMargin = 10 * time.Minute
+
+// tokenReuseMinTTL is the minimal remaining TTL of the stored token to reuse it
+const tokenReuseMinTTL = time.Minute
+
+// TokenRefreshStatus reports the state of the background token refresher
+type TokenRefreshStatus struct {
+	Running     bool      // Refresher goroutine is alive
+	LastRefresh time.Time // Time of the last successful token grant
+	NextRefresh time.Time // Time of the next planned token grant
+	LastError   error     // Error of the last failed grant. Reset on success
+	Failures    int       // Number of consecutive failed grants
+}
+
+// TokenRefreshStatus returns the state of the background token refresher
+func (c *Client) TokenRefreshStatus() TokenRefreshStatus {
+	c.tokenMu.Lock()
+	defer c.tokenMu.Unlock()
+
+	return c.refreshStatus
+}
+
+// watchToken refreshes the token before it expires until ctx is done
+// Failed grants are retried with exponential backoff
+func (c *Client) watchToken(ctx context.Context, ttl time.Duration) {
+	c.log.Info("Peplink token refresh goroutine started")
+	defer c.log.Info("Peplink token refresh goroutine stopped")
+
+	c.updateRefreshStatus(func(s *TokenRefreshStatus) { s.Running = true })
+	defer c.updateRefreshStatus(func(s *TokenRefreshStatus) {
+		s.Running = false
+		s.NextRefresh = time.Time{}
+	})
+
+	wait := tokenRefreshIn(ttl)
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+
+	for {
+		c.updateRefreshStatus(func(s *TokenRefreshStatus) { s.NextRefresh = time.Now().Add(wait) })
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C:
+		}
+
+		newTTL, err := c.authenticate(ctx, c.clientID, c.clientSecret)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			var failures int
+			c.updateRefreshStatus(func(s *TokenRefreshStatus) {
+				s.Failures++
+				s.LastError = err
+				failures = s.Failures
+			})
+			wait = c.refreshBackoff.duration(failures)
+			c.log.Error("Failed to refresh token", "error", err, "attempt", failures, "retryIn", wait.String())
+		} else {
+			c.updateRefreshStatus(func(s *TokenRefreshStatus) {
+				s.Failures = 0
+				s.LastError = nil
+			})
+			wait = tokenRefreshIn(newTTL)
+		}
+
+		timer.Reset(wait)
+	}
+}
+
+func (c *Client) updateRefreshStatus(update func(s *TokenRefreshStatus)) {
+	c.tokenMu.Lock()
+	defer c.tokenMu.Unlock()
+
+	update(&c.refreshStatus)
+}
+
+// tokenRefreshIn returns the delay before refreshing the token with the given TTL
+func tokenRefreshIn(ttl time.Duration) time.Duration {
+	margin := tokenRefreshMargin
+	if margin > ttl/5 {
+		margin = ttl / 5
+	}
+	wait := ttl - margin
+	if wait < time.Second {
+		wait = time.Second
+	}
+
+	return wait
+}
+
+// loadStoredToken picks up the token from the TokenStore
+// The token the Client already holds isn't reused because it is being refreshed or was rejected
+func (c *Client) loadStoredToken(ctx context.Context) (time.Duration, bool) {
+	if c.tokenStore == nil {
+		return 0, false
+	}
+
+	t, err := c.tokenStore.Load(ctx, c.tokenKey)
+	if err != nil {
+		if !errors.Is(err, ErrTokenNotFound) {
+			c.log.Warn("Failed to load token from the store", "error", err)
+		}
+		return 0, false
+	}
+
+	ttl := time.Until(t.ExpiresAt)
+	if t.AccessToken == "" || t.AccessToken == c.accessToken() || ttl < tokenReuseMinTTL {
+		return 0, false
+	}
+
+	c.setToken(t.AccessToken)
+	c.log.Info("Reused Peplink API token from the store", "TTL", ttl.Round(time.Second).String())
+
+	return ttl, true
+}
+
+func (c *Client) saveStoredToken(ctx context.Context, t Token) {
+	if c.tokenStore == nil {
+		return
+	}
+
+	err := c.tokenStore.Save(ctx, c.tokenKey, t)
+	if err != nil {
+		c.log.Warn("Failed to save token to the store", "error", err)
+	}
+}
+
+// forgetStoredToken removes the token from the TokenStore unless it was already replaced there
+func (c *Client) forgetStoredToken(ctx context.Context, token string) {
+	if c.tokenStore == nil {
+		return
+	}
+
+	t, err := c.tokenStore.Load(ctx, c.tokenKey)
+	if err != nil || t.AccessToken != token {
+		return
+	}
+
+	err = c.tokenStore.Delete(ctx, c.tokenKey)
+	if err != nil {
+		c.log.Warn("Failed to delete token from the store", "error", err)
+	}
+}
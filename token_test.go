@@ -0,0 +1,77 @@
+package peplink
+
+import (
+	"context"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTokenRefreshIn(t *testing.T) {
+	tests := []struct {
+		name string
+		ttl  time.Duration
+		want time.Duration
+	}{
+		{"long", 48 * time.Hour, 48*time.Hour - 10*time.Minute},
+		{"boundary", 50 * time.Minute, 40 * time.Minute},
+		{"short", 5 * time.Minute, 4 * time.Minute},
+		{"zero", 0, time.Second},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, tokenRefreshIn(tt.ttl))
+		})
+	}
+}
+
+func TestClient_watchToken(t *testing.T) {
+	var grants atomic.Int32
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/api/auth.token.grant", r.URL.Path)
+		w.Header().Set("Content-Type", "application/json")
+		if grants.Add(1) <= 2 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			w.Write([]byte(`{"stat": "fail", "code": 503, "message": "Service Unavailable"}`))
+			return
+		}
+		w.Write([]byte(`{"stat": "ok", "response": {"accessToken": "fresh", "expiresIn": "3600"}}`))
+	})
+	c.clientID = "client_id"
+	c.clientSecret = "client_secret"
+	c.refreshBackoff = backoff{min: 10 * time.Millisecond, max: 50 * time.Millisecond}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		c.watchToken(ctx, 0)
+		close(done)
+	}()
+
+	require.Eventually(t, func() bool {
+		s := c.TokenRefreshStatus()
+		return s.Running && s.Failures == 2 && s.LastError != nil
+	}, 3*time.Second, 5*time.Millisecond, "refresher must survive failed grants")
+
+	require.Eventually(t, func() bool {
+		s := c.TokenRefreshStatus()
+		return s.Failures == 0 && !s.LastRefresh.IsZero()
+	}, 3*time.Second, 5*time.Millisecond, "refresher must recover after failed grants")
+
+	s := c.TokenRefreshStatus()
+	require.NoError(t, s.LastError)
+	require.WithinDuration(t, time.Now().Add(50*time.Minute), s.NextRefresh, time.Minute)
+	require.Equal(t, "fresh", c.accessToken())
+	require.Equal(t, int32(3), grants.Load())
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("refresher must stop on ctx cancellation")
+	}
+	require.False(t, c.TokenRefreshStatus().Running)
+}
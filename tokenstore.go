@@ -0,0 +1,221 @@
+package peplink
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// ErrTokenNotFound is returned by TokenStore.Load when there is no token for the key
+var ErrTokenNotFound = errors.New("token not found")
+
+// TokenKey identifies the token in the TokenStore
+type TokenKey struct {
+	Endpoint string // Base URL of the device API
+	ClientID string // API client ID used for the grant
+}
+
+func (k TokenKey) String() string {
+	return k.Endpoint + "|" + k.ClientID
+}
+
+// Token is an access token granted by the device
+type Token struct {
+	AccessToken string    `json:"accessToken"`
+	ExpiresAt   time.Time `json:"expiresAt"`
+}
+
+// TokenStore persists access tokens so they can be reused across Client instances and process restarts
+type TokenStore interface {
+	// Load returns the stored token or ErrTokenNotFound
+	Load(ctx context.Context, key TokenKey) (Token, error)
+	Save(ctx context.Context, key TokenKey, token Token) error
+	Delete(ctx context.Context, key TokenKey) error
+}
+
+// MemoryTokenStore keeps tokens in memory. Safe for concurrent use
+type MemoryTokenStore struct {
+	mu     sync.Mutex
+	tokens map[TokenKey]Token
+}
+
+// NewMemoryTokenStore creates an empty in-memory TokenStore
+func NewMemoryTokenStore() *MemoryTokenStore {
+	return &MemoryTokenStore{tokens: map[TokenKey]Token{}}
+}
+
+func (s *MemoryTokenStore) Load(_ context.Context, key TokenKey) (Token, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	t, ok := s.tokens[key]
+	if !ok {
+		return Token{}, ErrTokenNotFound
+	}
+
+	return t, nil
+}
+
+func (s *MemoryTokenStore) Save(_ context.Context, key TokenKey, token Token) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.tokens[key] = token
+
+	return nil
+}
+
+func (s *MemoryTokenStore) Delete(_ context.Context, key TokenKey) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.tokens, key)
+
+	return nil
+}
+
+// FileTokenStore keeps tokens in a JSON file readable only by the owner (0600)
+// The file is replaced atomically on every change. Changes hold the lock of the sidecar file path+".lock",
+// so the processes sharing the file don't lose each other's tokens. The lock is advisory and taken on unix only
+type FileTokenStore struct {
+	mu   sync.Mutex
+	path string
+}
+
+// NewFileTokenStore creates a TokenStore backed by the file at path. The file is created on the first Save
+func NewFileTokenStore(path string) *FileTokenStore {
+	return &FileTokenStore{path: path}
+}
+
+func (s *FileTokenStore) Load(_ context.Context, key TokenKey) (Token, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tokens, err := s.read()
+	if err != nil {
+		return Token{}, err
+	}
+	t, ok := tokens[key.String()]
+	if !ok {
+		return Token{}, ErrTokenNotFound
+	}
+
+	return t, nil
+}
+
+func (s *FileTokenStore) Save(_ context.Context, key TokenKey, token Token) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	unlock, err := s.lock()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	tokens, err := s.read()
+	if err != nil {
+		return err
+	}
+	tokens[key.String()] = token
+
+	return s.write(tokens)
+}
+
+func (s *FileTokenStore) Delete(_ context.Context, key TokenKey) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	unlock, err := s.lock()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	tokens, err := s.read()
+	if err != nil {
+		return err
+	}
+	if _, ok := tokens[key.String()]; !ok {
+		return nil
+	}
+	delete(tokens, key.String())
+
+	return s.write(tokens)
+}
+
+// lock takes the lock shared with the other processes for the read-modify-write of the file
+func (s *FileTokenStore) lock() (func(), error) {
+	f, err := os.OpenFile(s.path+".lock", os.O_RDWR|os.O_CREATE, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open token lock file: %w", err)
+	}
+	err = lockFile(f)
+	if err != nil {
+		f.Close()
+		return nil, fmt.Errorf("failed to lock token file: %w", err)
+	}
+
+	return func() {
+		unlockFile(f)
+		f.Close()
+	}, nil
+}
+
+func (s *FileTokenStore) read() (map[string]Token, error) {
+	tokens := map[string]Token{}
+
+	buf, err := os.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return tokens, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read token file: %w", err)
+	}
+	if len(buf) == 0 {
+		return tokens, nil
+	}
+
+	err = json.Unmarshal(buf, &tokens)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal token file: %w", err)
+	}
+
+	return tokens, nil
+}
+
+func (s *FileTokenStore) write(tokens map[string]Token) error {
+	buf, err := json.Marshal(tokens)
+	if err != nil {
+		return fmt.Errorf("failed to marshal tokens: %w", err)
+	}
+
+	// CreateTemp creates the file with 0600 permissions
+	f, err := os.CreateTemp(filepath.Dir(s.path), ".peplink-token-*")
+	if err != nil {
+		return fmt.Errorf("failed to create token file: %w", err)
+	}
+	defer os.Remove(f.Name())
+
+	_, err = f.Write(buf)
+	if err != nil {
+		f.Close()
+		return fmt.Errorf("failed to write token file: %w", err)
+	}
+	err = f.Close()
+	if err != nil {
+		return fmt.Errorf("failed to write token file: %w", err)
+	}
+
+	err = os.Rename(f.Name(), s.path)
+	if err != nil {
+		return fmt.Errorf("failed to replace token file: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,12 @@
+//go:build !unix
+
+package peplink
+
+import "os"
+
+// lockFile does nothing. Only the goroutines of the process are serialized
+func lockFile(*os.File) error {
+	return nil
+}
+
+func unlockFile(*os.File) {}
@@ -0,0 +1,136 @@
+package peplink
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTokenStore(t *testing.T) {
+	stores := []struct {
+		name  string
+		store func(t *testing.T) TokenStore
+	}{
+		{"memory", func(t *testing.T) TokenStore { return NewMemoryTokenStore() }},
+		{"file", func(t *testing.T) TokenStore { return NewFileTokenStore(filepath.Join(t.TempDir(), "tokens.json")) }},
+	}
+	for _, tt := range stores {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			store := tt.store(t)
+			key := TokenKey{Endpoint: "https://192.168.50.1", ClientID: "client_id"}
+			other := TokenKey{Endpoint: "https://192.168.50.2", ClientID: "client_id"}
+			token := Token{AccessToken: "token", ExpiresAt: time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)}
+
+			_, err := store.Load(ctx, key)
+			require.ErrorIs(t, err, ErrTokenNotFound)
+
+			require.NoError(t, store.Save(ctx, key, token))
+			require.NoError(t, store.Save(ctx, other, Token{AccessToken: "other"}))
+
+			got, err := store.Load(ctx, key)
+			require.NoError(t, err)
+			require.Equal(t, token.AccessToken, got.AccessToken)
+			require.True(t, token.ExpiresAt.Equal(got.ExpiresAt))
+
+			require.NoError(t, store.Delete(ctx, key))
+			require.NoError(t, store.Delete(ctx, key), "Delete of the absent key must succeed")
+			_, err = store.Load(ctx, key)
+			require.ErrorIs(t, err, ErrTokenNotFound)
+
+			got, err = store.Load(ctx, other)
+			require.NoError(t, err)
+			require.Equal(t, "other", got.AccessToken)
+		})
+	}
+}
+
+func TestFileTokenStore_permissions(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tokens.json")
+	require.NoError(t, os.WriteFile(path, []byte("{}"), 0o644))
+
+	store := NewFileTokenStore(path)
+	require.NoError(t, store.Save(context.Background(), TokenKey{ClientID: "id"}, Token{AccessToken: "token"}))
+
+	fi, err := os.Stat(path)
+	require.NoError(t, err)
+	require.Equal(t, os.FileMode(0o600), fi.Mode().Perm())
+}
+
+func TestClient_tokenStoreReuse(t *testing.T) {
+	var grants atomic.Int32
+	srv := httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			require.Equal(t, "/api/auth.token.grant", r.URL.Path)
+			grants.Add(1)
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{"stat": "ok", "response": {"accessToken": "granted", "expiresIn": "172800"}}`))
+		}),
+	)
+	defer srv.Close()
+
+	ctx := context.Background()
+	key := TokenKey{Endpoint: srv.URL, ClientID: "client_id"}
+	newClient := func(store TokenStore) *Client {
+		c, err := NewClient(ctx,
+			WithHTTPBasicClientID("client_id"),
+			WithHTTPBasicClientSecret("client_secret"),
+			WithHTTPBasicURL(srv.URL),
+			WithTokenStore(store),
+		)
+		require.NoError(t, err)
+		t.Cleanup(func() { c.Close(ctx) })
+
+		return c
+	}
+
+	t.Run("reuse", func(t *testing.T) {
+		grants.Store(0)
+		store := NewMemoryTokenStore()
+		c1 := newClient(store)
+		c2 := newClient(store)
+
+		require.Equal(t, int32(1), grants.Load())
+		require.Equal(t, "granted", c1.accessToken())
+		require.Equal(t, "granted", c2.accessToken())
+		require.Eventually(t, func() bool {
+			next := c2.TokenRefreshStatus().NextRefresh
+			return next.Sub(time.Now().Add(48*time.Hour-10*time.Minute)).Abs() < time.Minute
+		}, time.Second, 5*time.Millisecond, "refresh must be planned by the TTL of the reused token")
+	})
+
+	t.Run("expired", func(t *testing.T) {
+		grants.Store(0)
+		store := NewMemoryTokenStore()
+		require.NoError(t, store.Save(ctx, key, Token{AccessToken: "expired", ExpiresAt: time.Now().Add(30 * time.Second)}))
+
+		c := newClient(store)
+		require.Equal(t, int32(1), grants.Load())
+		require.Equal(t, "granted", c.accessToken())
+
+		got, err := store.Load(ctx, key)
+		require.NoError(t, err)
+		require.Equal(t, "granted", got.AccessToken)
+	})
+
+	t.Run("rejected", func(t *testing.T) {
+		grants.Store(0)
+		store := NewMemoryTokenStore()
+		require.NoError(t, store.Save(ctx, key, Token{AccessToken: "revoked", ExpiresAt: time.Now().Add(time.Hour)}))
+
+		c := newClient(store)
+		require.Equal(t, int32(0), grants.Load())
+		require.Equal(t, "revoked", c.accessToken())
+
+		require.NoError(t, c.reauthenticate(ctx, "revoked"))
+		require.Equal(t, int32(1), grants.Load())
+		require.Equal(t, "granted", c.accessToken())
+	})
+}
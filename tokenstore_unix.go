@@ -0,0 +1,23 @@
+//go:build unix
+
+package peplink
+
+import (
+	"errors"
+	"os"
+	"syscall"
+)
+
+// lockFile blocks until the exclusive lock of the file is taken
+func lockFile(f *os.File) error {
+	for {
+		err := syscall.Flock(int(f.Fd()), syscall.LOCK_EX)
+		if !errors.Is(err, syscall.EINTR) {
+			return err
+		}
+	}
+}
+
+func unlockFile(f *os.File) {
+	syscall.Flock(int(f.Fd()), syscall.LOCK_UN)
+}
@@ -0,0 +1,47 @@
+//go:build unix
+
+package peplink
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileTokenStore_processes(t *testing.T) {
+	const writes = 20
+
+	// The test binary re-runs itself as the writer process
+	if path := os.Getenv("PEPLINK_TOKEN_STORE_PATH"); path != "" {
+		store := NewFileTokenStore(path)
+		for i := 0; i < writes; i++ {
+			key := TokenKey{ClientID: fmt.Sprintf("%s-%d", os.Getenv("PEPLINK_TOKEN_STORE_WRITER"), i)}
+			require.NoError(t, store.Save(context.Background(), key, Token{AccessToken: "token"}))
+		}
+		return
+	}
+
+	path := filepath.Join(t.TempDir(), "tokens.json")
+	writers := make([]*exec.Cmd, 4)
+	for i := range writers {
+		writers[i] = exec.Command(os.Args[0], "-test.run=^TestFileTokenStore_processes$")
+		writers[i].Env = append(os.Environ(), "PEPLINK_TOKEN_STORE_PATH="+path, fmt.Sprintf("PEPLINK_TOKEN_STORE_WRITER=%d", i))
+		require.NoError(t, writers[i].Start())
+	}
+	for _, w := range writers {
+		require.NoError(t, w.Wait())
+	}
+
+	store := NewFileTokenStore(path)
+	for i := range writers {
+		for j := 0; j < writes; j++ {
+			_, err := store.Load(context.Background(), TokenKey{ClientID: fmt.Sprintf("%d-%d", i, j)})
+			require.NoError(t, err, "token of writer %d lost", i)
+		}
+	}
+}
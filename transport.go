@@ -1,10 +1,14 @@
 package peplink
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"net/http"
+	"strings"
+
+	"github.com/go-resty/resty/v2"
 )
 
 type apiEnvelope struct {
@@ -15,35 +19,115 @@ type apiEnvelope struct {
 	Notice   interface{}     `json:"notice,omitempty"`  // Extra information about the API request (not part of the normal response)
 }
 
-func (c *Client) doRequest(ctx context.Context, endpoint, method string, body any) (json.RawMessage, error) {
+// doRequest calls the API endpoint and unwraps the envelope
+// Failed requests are retried according to the RetryPolicy
+func (c *Client) doRequest(ctx context.Context, req apiRequest) (json.RawMessage, error) {
+	if c.closed.Load() {
+		return nil, ErrClientClosed
+	}
+
+	for attempt := 1; ; attempt++ {
+		msg, err := c.doThrottledRequest(ctx, req)
+		if err == nil {
+			return msg, nil
+		}
+
+		wait, retry := c.retryDelay(ctx, req.method, attempt, err)
+		if !retry {
+			return nil, err
+		}
+
+		c.log.Warn("Retrying Peplink API request", "endpoint", req.endpoint, "method", req.method.String(),
+			"attempt", attempt+1, "maxAttempts", c.retry.MaxAttempts, "retryIn", wait.String(), "error", err)
+
+		if serr := sleepCtx(ctx, wait); serr != nil {
+			return nil, err
+		}
+	}
+}
+
+// doThrottledRequest waits for the request slot and the rate limit before calling the API endpoint
+func (c *Client) doThrottledRequest(ctx context.Context, req apiRequest) (json.RawMessage, error) {
+	release, err := c.throttle.acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	return c.doAuthenticatedRequest(ctx, req)
+}
+
+// doAuthenticatedRequest calls the API endpoint with the current credentials
+// If the access token or the admin session is rejected it is renewed and the request is replayed once
+func (c *Client) doAuthenticatedRequest(ctx context.Context, req apiRequest) (json.RawMessage, error) {
+	token := c.accessToken()
+
+	msg, err := c.doRequestWithToken(ctx, req, token)
+	if err == nil || !errors.Is(err, ErrUnauthorized) || !c.canReauthenticate() {
+		return msg, err
+	}
+
+	rerr := c.reauthenticate(ctx, token)
+	if rerr != nil {
+		return nil, fmt.Errorf("%w: failed to re-authenticate: %v", err, rerr)
+	}
+	// The replay is one more request to the device. The slot of the request is still held
+	werr := c.throttle.wait(ctx)
+	if werr != nil {
+		return nil, werr
+	}
+
+	return c.doRequestWithToken(ctx, req, c.accessToken())
+}
+
+func (c *Client) doRequestWithToken(ctx context.Context, req apiRequest, token string) (json.RawMessage, error) {
 	envelope := &apiEnvelope{}
 
 	request := c.httpClient.R().
 		SetContext(ctx).
 		SetResult(envelope).
-		SetError(envelope)
-
-	var (
-		err error
-	)
-	switch method {
-	case http.MethodGet:
-		_, err = request.Get(endpoint)
-	case http.MethodPost:
-		_, err = request.
-			SetBody(body).
-			Post(endpoint)
-	default:
-		return nil, fmt.Errorf("unknown method: %s Only GET and POST supported", method)
+		SetError(envelope).
+		SetQueryParamsFromValues(req.query)
+	// Admin sessions are sent as the cookie from the jar
+	if token != "" && c.authMode == authModeToken {
+		request.SetQueryParam("accessToken", token)
+	}
+	if req.body != nil {
+		request.SetBody(req.body)
+	}
+	if req.upload != nil {
+		request.SetFileReader(req.upload.field, req.upload.name, bytes.NewReader(req.upload.data))
 	}
 
+	resp, err := request.Execute(req.method.String(), req.endpoint)
 	if err != nil {
+		// The device answered with an error status but the body isn't an API envelope
+		if resp != nil && resp.RawResponse != nil && resp.IsError() {
+			return nil, newAPIError(req.endpoint, resp, &apiEnvelope{})
+		}
+
 		return nil, fmt.Errorf("failed to do HTTP request: %w", err)
 	}
 
 	if envelope.Stat != "ok" {
-		return nil, fmt.Errorf("status of the request isn't ok: stat='%s' body='%v'", envelope.Stat, envelope)
+		return nil, newAPIError(req.endpoint, resp, envelope)
 	}
 
 	return envelope.Response, nil
 }
+
+func newAPIError(endpoint string, resp *resty.Response, envelope *apiEnvelope) *APIError {
+	apiErr := &APIError{
+		StatusCode: resp.StatusCode(),
+		Code:       envelope.Code,
+		Message:    envelope.Message,
+		Endpoint:   endpoint,
+		Notice:     envelope.Notice,
+		RetryAfter: parseRetryAfter(resp.Header().Get("Retry-After")),
+	}
+	if envelope.Stat == "" && apiErr.Message == "" {
+		apiErr.Message = strings.TrimSpace(string(resp.Body()))
+	}
+
+	return apiErr
+}
@@ -0,0 +1,178 @@
+package peplink
+
+import (
+	"context"
+	"fmt"
+	"sort"
+)
+
+// WanConfig is the change of the WAN connection settings. nil fields are not changed
+type WanConfig struct {
+	Enable   *bool `json:"enable,omitempty"`
+	Priority *int  `json:"priority,omitempty"` // Priority of the WAN. Starts from 1
+	Groupset *int  `json:"groupset,omitempty"` // Group set of the WAN connection
+}
+
+// WanChange is the difference between the current and the proposed setting
+type WanChange struct {
+	Field string // JSON name of the setting
+	From  any    // Current value. nil if the device doesn't report it
+	To    any    // Proposed value
+}
+
+// WanConfigResult is the outcome of the WAN configuration
+type WanConfigResult struct {
+	Status  WanStatus   // Status after the change. The current status on dry run or if nothing changes
+	Changes []WanChange // Settings which differ from the current ones. Empty if nothing changes
+}
+
+// wanConfigRequest is the body of /api/config.wan.connection
+type wanConfigRequest struct {
+	ID int `json:"id"`
+	WanConfig
+}
+
+// EnableWan enables the WAN connection
+func (c *Client) EnableWan(ctx context.Context, id int, opts ...CommandOption) (WanConfigResult, error) {
+	enable := true
+	return c.ConfigureWan(ctx, id, WanConfig{Enable: &enable}, opts...)
+}
+
+// DisableWan disables the WAN connection
+func (c *Client) DisableWan(ctx context.Context, id int, opts ...CommandOption) (WanConfigResult, error) {
+	enable := false
+	return c.ConfigureWan(ctx, id, WanConfig{Enable: &enable}, opts...)
+}
+
+// SetWanPriority changes the priority of the WAN connection
+func (c *Client) SetWanPriority(ctx context.Context, id, priority int, opts ...CommandOption) (WanConfigResult, error) {
+	return c.ConfigureWan(ctx, id, WanConfig{Priority: &priority}, opts...)
+}
+
+// SetWanGroupset changes the group set of the WAN connection
+func (c *Client) SetWanGroupset(ctx context.Context, id, groupset int, opts ...CommandOption) (WanConfigResult, error) {
+	return c.ConfigureWan(ctx, id, WanConfig{Groupset: &groupset}, opts...)
+}
+
+// ConfigureWan changes the settings of the WAN connection and applies the config
+// Requires WithConfirm. WithDryRun returns the diff without changing the device
+// Use ConfigureWans to change several WAN connections with a single apply
+func (c *Client) ConfigureWan(ctx context.Context, id int, cfg WanConfig, opts ...CommandOption) (WanConfigResult, error) {
+	results, err := c.ConfigureWans(ctx, map[int]WanConfig{id: cfg}, opts...)
+	return results[id], err
+}
+
+// ConfigureWans stages the settings of every WAN connection and applies the config once
+// Requires WithConfirm. WithDryRun returns the diffs without changing the device
+// If the config isn't applied, the error says so and the results hold the staged changes
+func (c *Client) ConfigureWans(ctx context.Context, cfgs map[int]WanConfig, opts ...CommandOption) (map[int]WanConfigResult, error) {
+	o, err := newCommandOptions("wan configuration", opts)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]int, 0, len(cfgs))
+	for id, cfg := range cfgs {
+		if cfg.Priority != nil && *cfg.Priority < 1 {
+			return nil, fmt.Errorf("failed to configure wan %d: priority must be positive, got %d", id, *cfg.Priority)
+		}
+		if cfg.Groupset != nil && *cfg.Groupset < 0 {
+			return nil, fmt.Errorf("failed to configure wan %d: groupset must not be negative, got %d", id, *cfg.Groupset)
+		}
+		ids = append(ids, id)
+	}
+	if len(ids) == 0 {
+		return map[int]WanConfigResult{}, nil
+	}
+	sort.Ints(ids)
+
+	current, err := c.StatusWanConnection(ctx, WithWanIDs(ids...))
+	if err != nil {
+		return nil, fmt.Errorf("failed to configure wans %v: %w", ids, err)
+	}
+	results := make(map[int]WanConfigResult, len(ids))
+	changed := []int{}
+	for _, id := range ids {
+		status, ok := WanByID(current, id)
+		if !ok {
+			return nil, fmt.Errorf("failed to configure wan %d: no wan connection with id %d", id, id)
+		}
+		results[id] = WanConfigResult{Status: status, Changes: wanChanges(status, cfgs[id])}
+		if len(results[id].Changes) > 0 {
+			changed = append(changed, id)
+		}
+	}
+	if o.dryRun || len(changed) == 0 {
+		return results, nil
+	}
+
+	c.log.Info("Configuring Peplink WAN connections", "ids", changed)
+	for i, id := range changed {
+		_, err = c.doRequest(ctx, postRequest("/api/config.wan.connection", wanConfigRequest{ID: id, WanConfig: cfgs[id]}))
+		if err != nil && i > 0 {
+			return results, fmt.Errorf("failed to configure wan %d, config of wans %v is staged but not applied: %w", id, changed[:i], err)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to configure wan %d: %w", id, err)
+		}
+	}
+	err = c.ApplyConfig(ctx, WithConfirm())
+	if err != nil {
+		return results, fmt.Errorf("failed to configure wans %v, config is staged but not applied: %w", changed, err)
+	}
+
+	applied, err := c.StatusWanConnection(ctx, WithWanIDs(changed...))
+	if err != nil {
+		return results, fmt.Errorf("failed to get status of the configured wans %v: %w", changed, err)
+	}
+	for _, id := range changed {
+		status, ok := WanByID(applied, id)
+		if !ok {
+			return results, fmt.Errorf("failed to get status of the configured wan %d: no wan connection with id %d", id, id)
+		}
+		result := results[id]
+		result.Status = status
+		results[id] = result
+	}
+
+	return results, nil
+}
+
+// ApplyConfig applies the saved configuration changes
+// Requires WithConfirm. WithDryRun does nothing
+func (c *Client) ApplyConfig(ctx context.Context, opts ...CommandOption) error {
+	o, err := newCommandOptions("config apply", opts)
+	if err != nil {
+		return err
+	}
+	if o.dryRun {
+		return nil
+	}
+
+	_, err = c.doRequest(ctx, postRequest("/api/cmd.config.apply", nil))
+	if err != nil {
+		return fmt.Errorf("failed to apply config: %w", err)
+	}
+
+	return nil
+}
+
+// wanChanges returns the settings of cfg which differ from the status
+func wanChanges(s WanStatus, cfg WanConfig) []WanChange {
+	changes := []WanChange{}
+	if cfg.Enable != nil && *cfg.Enable != s.Enable {
+		changes = append(changes, WanChange{Field: "enable", From: s.Enable, To: *cfg.Enable})
+	}
+	if cfg.Priority != nil && (s.Priority == nil || *s.Priority != *cfg.Priority) {
+		var from any
+		if s.Priority != nil {
+			from = *s.Priority
+		}
+		changes = append(changes, WanChange{Field: "priority", From: from, To: *cfg.Priority})
+	}
+	if cfg.Groupset != nil && *cfg.Groupset != s.Groupset {
+		changes = append(changes, WanChange{Field: "groupset", From: s.Groupset, To: *cfg.Groupset})
+	}
+
+	return changes
+}
@@ -0,0 +1,284 @@
+package peplink
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// wanConfigStatus is the status of WAN 1 and the disabled WAN 3 before the change
+const wanConfigStatus = `{"stat": "ok", "response": {
+	"1": {"name": "WAN 1", "enable": true, "priority": 1, "groupset": 1},
+	"3": {"name": "WAN 3", "enable": false, "groupset": 1},
+	"order": [1, 3]
+}}`
+
+// wanConfigServer answers the WAN status with wanConfigStatus until the config is applied and with after since then
+// It records the POST requests
+type wanConfigServer struct {
+	mu        sync.Mutex
+	after     string
+	applied   bool
+	failApply bool     // the apply command fails
+	calls     []string // paths of the POST requests
+	config    string   // body of the last config request
+}
+
+func newWanConfigServer(t *testing.T, after string) (*wanConfigServer, *Client) {
+	t.Helper()
+
+	s := &wanConfigServer{after: after}
+	c := newTestClient(t, s.serveHTTP)
+
+	return s, c
+}
+
+func (s *wanConfigServer) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	if r.Method == http.MethodPost {
+		s.calls = append(s.calls, r.URL.Path)
+	}
+
+	switch r.URL.Path {
+	case "/api/status.wan.connection":
+		if s.applied {
+			w.Write([]byte(s.after))
+			return
+		}
+		w.Write([]byte(wanConfigStatus))
+	case "/api/config.wan.connection":
+		b, _ := io.ReadAll(r.Body)
+		s.config = string(b)
+		w.Write([]byte(`{"stat": "ok"}`))
+	case "/api/cmd.config.apply":
+		if s.failApply {
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write([]byte(`{"stat": "fail", "code": 500, "message": "Apply failed"}`))
+			return
+		}
+		s.applied = true
+		w.Write([]byte(`{"stat": "ok"}`))
+	default:
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"stat": "fail", "code": 404, "message": "Not Found"}`))
+	}
+}
+
+func TestClient_ConfigureWan(t *testing.T) {
+	applied := []string{"/api/config.wan.connection", "/api/cmd.config.apply"}
+	tests := []struct {
+		name       string
+		configure  func(c *Client) (WanConfigResult, error)
+		after      string // status once the config is applied
+		want       WanConfigResult
+		wantErr    bool
+		wantCalls  []string
+		wantConfig string
+	}{
+		{"not confirmed",
+			func(c *Client) (WanConfigResult, error) {
+				return c.DisableWan(context.Background(), 1)
+			},
+			"",
+			WanConfigResult{},
+			true,
+			nil,
+			"",
+		},
+		{"disable dry run",
+			func(c *Client) (WanConfigResult, error) {
+				return c.DisableWan(context.Background(), 1, WithDryRun())
+			},
+			"",
+			WanConfigResult{
+				Status:  WanStatus{ID: 1, Name: "WAN 1", Enable: true, Priority: ptr(1), Groupset: 1},
+				Changes: []WanChange{{Field: "enable", From: true, To: false}},
+			},
+			false,
+			nil,
+			"",
+		},
+		{"disable",
+			func(c *Client) (WanConfigResult, error) {
+				return c.DisableWan(context.Background(), 1, WithConfirm())
+			},
+			`{"stat": "ok", "response": {"1": {"name": "WAN 1", "enable": false, "groupset": 1}, "order": [1]}}`,
+			WanConfigResult{
+				Status:  WanStatus{ID: 1, Name: "WAN 1", Enable: false, Groupset: 1},
+				Changes: []WanChange{{Field: "enable", From: true, To: false}},
+			},
+			false,
+			applied,
+			`{"id": 1, "enable": false}`,
+		},
+		{"enable",
+			func(c *Client) (WanConfigResult, error) {
+				return c.EnableWan(context.Background(), 3, WithConfirm())
+			},
+			`{"stat": "ok", "response": {"3": {"name": "WAN 3", "enable": true, "priority": 2, "groupset": 1}, "order": [3]}}`,
+			WanConfigResult{
+				Status:  WanStatus{ID: 3, Name: "WAN 3", Enable: true, Priority: ptr(2), Groupset: 1},
+				Changes: []WanChange{{Field: "enable", From: false, To: true}},
+			},
+			false,
+			applied,
+			`{"id": 3, "enable": true}`,
+		},
+		{"priority of disabled wan",
+			func(c *Client) (WanConfigResult, error) {
+				return c.SetWanPriority(context.Background(), 3, 3, WithDryRun())
+			},
+			"",
+			WanConfigResult{
+				Status:  WanStatus{ID: 3, Name: "WAN 3", Groupset: 1},
+				Changes: []WanChange{{Field: "priority", From: nil, To: 3}},
+			},
+			false,
+			nil,
+			"",
+		},
+		{"priority",
+			func(c *Client) (WanConfigResult, error) {
+				return c.SetWanPriority(context.Background(), 1, 3, WithConfirm())
+			},
+			`{"stat": "ok", "response": {"1": {"name": "WAN 1", "enable": true, "priority": 3, "groupset": 1}, "order": [1]}}`,
+			WanConfigResult{
+				Status:  WanStatus{ID: 1, Name: "WAN 1", Enable: true, Priority: ptr(3), Groupset: 1},
+				Changes: []WanChange{{Field: "priority", From: 1, To: 3}},
+			},
+			false,
+			applied,
+			`{"id": 1, "priority": 3}`,
+		},
+		{"groupset",
+			func(c *Client) (WanConfigResult, error) {
+				return c.SetWanGroupset(context.Background(), 1, 2, WithConfirm())
+			},
+			`{"stat": "ok", "response": {"1": {"name": "WAN 1", "enable": true, "priority": 1, "groupset": 2}, "order": [1]}}`,
+			WanConfigResult{
+				Status:  WanStatus{ID: 1, Name: "WAN 1", Enable: true, Priority: ptr(1), Groupset: 2},
+				Changes: []WanChange{{Field: "groupset", From: 1, To: 2}},
+			},
+			false,
+			applied,
+			`{"id": 1, "groupset": 2}`,
+		},
+		{"nothing changes",
+			func(c *Client) (WanConfigResult, error) {
+				return c.EnableWan(context.Background(), 1, WithConfirm())
+			},
+			"",
+			WanConfigResult{
+				Status:  WanStatus{ID: 1, Name: "WAN 1", Enable: true, Priority: ptr(1), Groupset: 1},
+				Changes: []WanChange{},
+			},
+			false,
+			nil,
+			"",
+		},
+		{"invalid priority",
+			func(c *Client) (WanConfigResult, error) {
+				return c.SetWanPriority(context.Background(), 1, 0, WithConfirm())
+			},
+			"",
+			WanConfigResult{},
+			true,
+			nil,
+			"",
+		},
+		{"unknown wan",
+			func(c *Client) (WanConfigResult, error) {
+				return c.DisableWan(context.Background(), 2, WithConfirm())
+			},
+			"",
+			WanConfigResult{},
+			true,
+			nil,
+			"",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, c := newWanConfigServer(t, tt.after)
+
+			got, err := tt.configure(c)
+			require.Equal(t, tt.wantErr, err != nil, "ConfigureWan() error = %v, wantErr %v", err, tt.wantErr)
+			require.Equal(t, tt.want, got)
+			require.Equal(t, tt.wantCalls, s.calls)
+			if tt.wantConfig == "" {
+				require.Empty(t, s.config)
+			} else {
+				require.JSONEq(t, tt.wantConfig, s.config)
+			}
+		})
+	}
+}
+
+func TestClient_ConfigureWans(t *testing.T) {
+	// WAN 3 takes over the priority of WAN 1
+	cfgs := map[int]WanConfig{
+		1: {Priority: ptr(2)},
+		3: {Enable: ptr(true), Priority: ptr(1)},
+	}
+	changes := map[int][]WanChange{
+		1: {{Field: "priority", From: 1, To: 2}},
+		3: {{Field: "enable", From: false, To: true}, {Field: "priority", From: nil, To: 1}},
+	}
+	staged := []string{"/api/config.wan.connection", "/api/config.wan.connection", "/api/cmd.config.apply"}
+
+	t.Run("applied once", func(t *testing.T) {
+		s, c := newWanConfigServer(t, `{"stat": "ok", "response": {
+			"1": {"name": "WAN 1", "enable": true, "priority": 2, "groupset": 1},
+			"3": {"name": "WAN 3", "enable": true, "priority": 1, "groupset": 1},
+			"order": [3, 1]
+		}}`)
+
+		got, err := c.ConfigureWans(context.Background(), cfgs, WithConfirm())
+		require.NoError(t, err)
+		require.Equal(t, map[int]WanConfigResult{
+			1: {Status: WanStatus{ID: 1, Name: "WAN 1", Enable: true, Priority: ptr(2), Groupset: 1}, Changes: changes[1]},
+			3: {Status: WanStatus{ID: 3, Name: "WAN 3", Enable: true, Priority: ptr(1), Groupset: 1}, Changes: changes[3]},
+		}, got)
+		require.Equal(t, staged, s.calls)
+		require.JSONEq(t, `{"id": 3, "enable": true, "priority": 1}`, s.config)
+	})
+
+	t.Run("dry run", func(t *testing.T) {
+		s, c := newWanConfigServer(t, "")
+
+		got, err := c.ConfigureWans(context.Background(), cfgs, WithDryRun())
+		require.NoError(t, err)
+		require.Equal(t, changes[1], got[1].Changes)
+		require.Equal(t, changes[3], got[3].Changes)
+		require.Empty(t, s.calls)
+	})
+
+	t.Run("apply failed", func(t *testing.T) {
+		s, c := newWanConfigServer(t, "")
+		s.failApply = true
+
+		got, err := c.ConfigureWans(context.Background(), cfgs, WithConfirm())
+		require.ErrorContains(t, err, "config is staged but not applied")
+		require.Equal(t, changes[1], got[1].Changes, "staged changes must be returned")
+		require.Equal(t, changes[3], got[3].Changes, "staged changes must be returned")
+		require.Equal(t, staged, s.calls)
+	})
+}
+
+func TestClient_ApplyConfig(t *testing.T) {
+	s, c := newWanConfigServer(t, "")
+
+	require.ErrorIs(t, c.ApplyConfig(context.Background()), ErrNotConfirmed)
+	require.NoError(t, c.ApplyConfig(context.Background(), WithDryRun()))
+	require.Empty(t, s.calls)
+
+	require.NoError(t, c.ApplyConfig(context.Background(), WithConfirm()))
+	require.Equal(t, []string{"/api/cmd.config.apply"}, s.calls)
+}
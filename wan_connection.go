@@ -2,14 +2,16 @@ package peplink
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
-	"net/http"
-
-	"github.com/jmespath/go-jmespath"
+	"strconv"
+	"strings"
 )
 
 type WanStatus struct {
+	// ID of the WAN connection. Taken from the key of the response
+	ID int `json:"-"`
+	// Queried with lite=yes. The fields omitted in the lite mode are nil
+	Lite bool `json:"-"`
 	// Name of the WAN connection
 	Name string `json:"name"`
 	// LED color for UI { empty, gray, red, yellow, green, flash }
@@ -20,6 +22,8 @@ type WanStatus struct {
 	Enable bool `json:"enable"`
 	// WAN is locked or not
 	Locked bool `json:"locked"`
+	// WAN is used for the management traffic only
+	ManagementOnly bool `json:"managementOnly"`
 	// Only appear if Connection is scheduled and currently off
 	ScheduledOff bool `json:"scheduledOff"`
 	// WAN status message
@@ -38,34 +42,38 @@ type WanStatus struct {
 	// Before fw8.0.1, it will return “gobi”
 	// { modem, wireless, gobi, cellular, ipsec, adsl, ethernet }
 	VirtualType string `json:"virtualType"`
-	// Priority of the WAN. The field will not appear if the WAN is disabled
-	Priority int `json:"priority"`
+	// WAN is a virtual connection or not
+	Virtual bool `json:"virtual"`
+	// Priority of the WAN. The field will not appear if the WAN is disabled. nil if absent
+	Priority *int `json:"priority"`
 	//Group set of the WAN connection
 	Groupset int `json:"groupset"`
 	// IP address
 	Ip string `json:"ip"`
-	// Subnet mask. The field will not appear if ip is not exist or lite=yes
-	Mask int `json:"mask"`
-	// Gateway. The field will not appear if ip is not exist or lite=yes
-	Gateway string `json:"gateway"`
-	// Connection method, DHCP or Static IP. The field will not appear if lite=yes
+	// Subnet mask. The field will not appear if ip is not exist or lite=yes. nil if absent
+	Mask *int `json:"mask"`
+	// Gateway. The field will not appear if ip is not exist or lite=yes. nil if absent
+	Gateway *string `json:"gateway"`
+	// Connection method, DHCP or Static IP. The field will not appear if lite=yes. nil if absent
 	//{ dhcp static }
-	Method string `json:"method"`
-	// Connection mode. The field will not appear if lite=yes
+	Method *string `json:"method"`
+	// Connection mode. The field will not appear if lite=yes. nil if absent
 	// { NAT, IP Forwarding }
-	Mode string `json:"mode"`
-	// DNS Server list. The field will not appear if lite=yes
+	Mode *string `json:"routingMode"`
+	// DNS Server list. The field will not appear if lite=yes. nil if absent
 	Dns []string `json:"dns"`
-	// Additional IP address list. The field will not appear if lite=yes
+	// Additional IP address list. The field will not appear if lite=yes. nil if absent
 	AditionalIp []string `json:"aditionalIp"`
-	// MTU value. The field will not appear if auto or lite=yes
+	// MTU value. The field will not appear if auto or lite=yes. nil if absent
 	// [576, 9000]
-	MTU int `json:"mtu"`
-	// MSS value. The field will not appear if auto or lite=yes
+	MTU *int `json:"mtu"`
+	// MSS value. The field will not appear if auto or lite=yes. nil if absent
 	// [536, 8960]
-	MSS int `json:"mss"`
-	// MAC address. The field will not appear if lite=yes
-	Mac string `json:"mac"`
+	MSS *int `json:"mss"`
+	// MAC address. The field will not appear if lite=yes. nil if absent
+	Mac *string `json:"mac"`
+	// Bandwidth allowance monitor of the WAN
+	BandwidthAllowanceMonitor BandwidthAllowanceMonitorObj `json:"bandwidthAllowanceMonitor"`
 	// WAN connection detail for wireless. The field will only appear if type is wifi
 	Wireless WifiInfo `json:"wireless"`
 	// WAN connection detail for modem. The field will only appear if type is modem
@@ -77,21 +85,22 @@ type WanStatus struct {
 }
 
 // Signal represents the signal information.
+// The fields are nil if the device doesn't report them
 type Signal struct {
-	RSSI     int     `json:"rssi,omitempty"`     // Received Signal Strength Indicator (RSSI), only appear in Gobi and Modem
-	SINR     float64 `json:"sinr,omitempty"`     // Signal to Interference plus Noise Ratio (SINR), only appear in Gobi and Modem
-	SNR      float64 `json:"snr,omitempty"`      // Signal-to-noise ratio (SNR), only appear in Gobi and has value
-	ECIO     float64 `json:"ecio,omitempty"`     // Energy to Interference Ratio (Ec/Io), only appear in Gobi and has value
-	RSRP     float64 `json:"rsrp,omitempty"`     // Reference Signal Received Power (RSRP), only appear in Gobi and Modem
-	RSRQ     float64 `json:"rsrq,omitempty"`     // Reference Signal Received Quality (RSRQ), only appear in Gobi
-	Strength float64 `json:"strength,omitempty"` // Wi-Fi signal strength, only appear in Wifi
+	RSSI     *int     `json:"rssi,omitempty"`     // Received Signal Strength Indicator (RSSI), only appear in Gobi and Modem
+	SINR     *float64 `json:"sinr,omitempty"`     // Signal to Interference plus Noise Ratio (SINR), only appear in Gobi and Modem
+	SNR      *float64 `json:"snr,omitempty"`      // Signal-to-noise ratio (SNR), only appear in Gobi and has value
+	ECIO     *float64 `json:"ecio,omitempty"`     // Energy to Interference Ratio (Ec/Io), only appear in Gobi and has value
+	RSRP     *float64 `json:"rsrp,omitempty"`     // Reference Signal Received Power (RSRP), only appear in Gobi and Modem
+	RSRQ     *float64 `json:"rsrq,omitempty"`     // Reference Signal Received Quality (RSRQ), only appear in Gobi
+	Strength *float64 `json:"strength,omitempty"` // Wi-Fi signal strength, only appear in Wifi
 }
 
 // WifiInfo represents information about Wi-Fi networks.
 type WifiInfo struct {
-	SSID   string `json:"ssid"`   // SSID of the Wifi. The field will not appear if lite=yes
-	BSSID  string `json:"bssid"`  // BSSID. The field will not appear if lite=yes
-	Signal Signal `json:"signal"` // Signal information
+	SSID   *string `json:"ssid"`   // SSID of the Wifi. The field will not appear if lite=yes. nil if absent
+	BSSID  *string `json:"bssid"`  // BSSID. The field will not appear if lite=yes. nil if absent
+	Signal Signal  `json:"signal"` // Signal information
 }
 
 // ModemObj represents modem adaptor information.
@@ -122,8 +131,8 @@ type GobiObj struct {
 	Network       string        `json:"network"`       // Network name (deprecated in fw8.0.1)
 	MobileType    string        `json:"mobileType"`    // Network name (use "mobileType" in fw8.0.1 or later)
 	ModulePowerOn bool          `json:"modulePowerOn"` // TODO: doc
-	SIM           []SIMGroupObj `json:"-"`
-	// SIM information. Extracted not from JSON because contains order field
+	SIM           []SIMGroupObj `json:"-"`             // SIM information. Decoded from the "order"-keyed "sim" object in UnmarshalJSON
+
 	RemoteSIM               RemoteSIMObj            `json:"remoteSim,omitempty"`     // Remote SIM information (only when remote SIM is enabled)
 	SpeedFusionConnect5gLTE SpeedFusionConnect5gLTE `json:"speedfusionConnect5gLte"` // TODO: doc
 	Carrier                 CarrierObj              `json:"carrier"`                 // Carrier information
@@ -137,8 +146,25 @@ type GobiObj struct {
 	MCC                     string                  `json:"mcc"`                     // Mobile Country Code (MCC)
 	MNC                     string                  `json:"mnc"`                     // Mobile Network Code (MNC)
 	CellTower               CellTowerObj            `json:"cellTower"`               // Cell Tower information
-	Model                   string                  `json:"model"`
-	Firmware                string                  `json:"firmware"`
+	Manufacturer            string                  `json:"manufacturer"`            // Module manufacturer
+	Model                   string                  `json:"model"`                   // Module model
+	Firmware                string                  `json:"firmware"`                // Module firmware
+}
+
+func (g *GobiObj) UnmarshalJSON(data []byte) error {
+	type gobiObj GobiObj
+	aux := struct {
+		*gobiObj
+		SIM OrderedMap[SIMGroupObj] `json:"sim"`
+	}{gobiObj: (*gobiObj)(g)}
+
+	err := unmarshalJSON(data, &aux)
+	if err != nil {
+		return err
+	}
+	g.SIM = aux.SIM.Values()
+
+	return nil
 }
 
 // RatObj represents Radio Access Technology (RAT) information.
@@ -149,19 +175,21 @@ type RatObj struct {
 
 // BandObj represents cellular band information.
 type BandObj struct {
-	Name   string    `json:"name"`   // Band Name
-	Signal SignalObj `json:"signal"` // Signal information
+	Name         string    `json:"name"`         // Band Name
+	Channel      int       `json:"channel"`      // Channel number (EARFCN for LTE)
+	ChannelWidth string    `json:"channelWidth"` // Channel width, e.g. "20 MHz"
+	Signal       SignalObj `json:"signal"`       // Signal information
 }
 
 // SignalObj represents signal information.
 type SignalObj struct {
-	RSSI     int     `json:"rssi,omitempty"`     // Received Signal Strength Indicator (RSSI)
-	SINR     float64 `json:"sinr,omitempty"`     // Signal to Interference plus Noise Ratio (SINR)
-	SNR      float64 `json:"snr,omitempty"`      // Signal-to-noise ratio (SNR)
-	ECIO     float64 `json:"ecio,omitempty"`     // Energy to Interference Ratio (Ec/Io)
-	RSRP     float64 `json:"rsrp,omitempty"`     // Reference Signal Received Power (RSRP)
-	RSRQ     float64 `json:"rsrq,omitempty"`     // Reference Signal Received Quality (RSRQ)
-	Strength float64 `json:"strength,omitempty"` // Wi-Fi signal strength
+	RSSI     *int     `json:"rssi,omitempty"`     // Received Signal Strength Indicator (RSSI)
+	SINR     *float64 `json:"sinr,omitempty"`     // Signal to Interference plus Noise Ratio (SINR)
+	SNR      *float64 `json:"snr,omitempty"`      // Signal-to-noise ratio (SNR)
+	ECIO     *float64 `json:"ecio,omitempty"`     // Energy to Interference Ratio (Ec/Io)
+	RSRP     *float64 `json:"rsrp,omitempty"`     // Reference Signal Received Power (RSRP)
+	RSRQ     *float64 `json:"rsrq,omitempty"`     // Reference Signal Received Quality (RSRQ)
+	Strength *float64 `json:"strength,omitempty"` // Wi-Fi signal strength
 }
 
 // SIMGroupObj represents a group of SIM cards.
@@ -170,8 +198,11 @@ type SIMGroupObj struct {
 	SimCardDetected           bool                         `json:"simCardDetected"`
 	Imsi                      string                       `json:"imsi"`
 	Iccid                     string                       `json:"iccid"`
+	Mtn                       string                       `json:"mtn,omitempty"`
 	AutoApn                   bool                         `json:"autoApn"`
 	Apn                       string                       `json:"apn"`
+	Username                  string                       `json:"username,omitempty"`
+	Password                  string                       `json:"password,omitempty"`
 	BandwidthAllowanceMonitor BandwidthAllowanceMonitorObj `json:"bandwidthAllowanceMonitor"`
 }
 type BandwidthAllowanceMonitorObj struct {
@@ -192,8 +223,8 @@ type RemoteSIMObj struct {
 
 // CarrierObj represents carrier information.
 type CarrierObj struct {
-	Name    string `json:"name"`    // Carrier name
-	Country string `json:"country"` // Carrier country (field does not appear if lite = yes)
+	Name    string  `json:"name"`    // Carrier name
+	Country *string `json:"country"` // Carrier country (field does not appear if lite = yes). nil if absent
 }
 
 // MEIDObj represents Mobile Equipment Identifier (MEID) information.
@@ -234,75 +265,82 @@ type SpeedFusionConnect5gLTE struct {
 	Iccid  string `json:"iccid"`
 }
 
+// WanStatusOption is the option of the StatusWanConnection query
+type WanStatusOption func(*wanStatusQuery)
+
+type wanStatusQuery struct {
+	ids  []int
+	lite bool
+}
+
+// WithWanIDs queries only the WAN connections with the IDs
+func WithWanIDs(ids ...int) WanStatusOption {
+	return func(q *wanStatusQuery) {
+		q.ids = append(q.ids, ids...)
+	}
+}
+
+// WithWanLite queries the WAN connections with lite=yes
+// The fields the device omits in the lite mode are nil
+func WithWanLite() WanStatusOption {
+	return func(q *wanStatusQuery) {
+		q.lite = true
+	}
+}
+
 // StatusWanConnection returns the status of the WAN connections
-func (c *Client) StatusWanConnection(ctx context.Context) ([]WanStatus, error) {
-	msg, err := c.doRequest(ctx, "/api/status.wan.connection", http.MethodGet, nil)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get wan status via http: %w", err)
+// All the WAN connections are queried with full details unless the options are given
+func (c *Client) StatusWanConnection(ctx context.Context, opts ...WanStatusOption) ([]WanStatus, error) {
+	q := wanStatusQuery{}
+	for _, o := range opts {
+		o(&q)
 	}
 
-	var buf interface{}
+	req := getRequest("/api/status.wan.connection")
+	if len(q.ids) > 0 {
+		ids := make([]string, 0, len(q.ids))
+		for _, id := range q.ids {
+			ids = append(ids, strconv.Itoa(id))
+		}
+		req = req.withQuery("id", strings.Join(ids, " "))
+	}
+	if q.lite {
+		req = req.withQuery("lite", "yes")
+	}
 
-	err = json.Unmarshal(msg, &buf)
+	wans, err := doRequestInto[OrderedMap[WanStatus]](ctx, c, req)
 	if err != nil {
-		return nil, fmt.Errorf("failed to unmarshal json: %w", err)
+		return nil, fmt.Errorf("failed to get wan status via http: %w", err)
 	}
 
-	orderI, err := jmespath.Search("order", buf)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get firmware version from json: %w", err)
+	statuses := make([]WanStatus, 0, len(wans))
+	for _, wan := range wans {
+		wan.Value.ID = wan.ID
+		wan.Value.Lite = q.lite
+		statuses = append(statuses, wan.Value)
 	}
-	statuses := []WanStatus{}
 
-	for _, oi := range orderI.([]interface{}) {
-		i, ok := oi.(float64)
-		if !ok {
-			return nil, fmt.Errorf("failed to get wan status: order is not a float64")
-		}
-		wanI, err := jmespath.Search(fmt.Sprintf("\"%d\"", int(i)), buf)
-		if err != nil {
-			return nil, fmt.Errorf("failed to get wan status from json: %w", err)
-		}
-		// Do marshal/unmarshal to workaround interface{} hussle
-		buf, err := json.Marshal(wanI)
-		if err != nil {
-			return nil, fmt.Errorf("failed to get wan status from json: %w", err)
-		}
-		wan := WanStatus{}
-		err = json.Unmarshal(buf, &wan)
-		if err != nil {
-			return nil, fmt.Errorf("failed to get wan status from json: %w", err)
-		}
-		// Get Sim info
-		simI, err := jmespath.Search("cellular.sim.order", wanI)
-		if err != nil {
-			return nil, fmt.Errorf("failed to get sim wan status from json: %w", err)
+	return statuses, nil
+}
+
+// WanByID returns the WAN connection with the ID
+func WanByID(statuses []WanStatus, id int) (WanStatus, bool) {
+	for _, s := range statuses {
+		if s.ID == id {
+			return s, true
 		}
-		if simI != nil {
-			for _, si := range simI.([]interface{}) {
-				s, ok := si.(float64)
-				if !ok {
-					return nil, fmt.Errorf("failed to get wan status: order is not a float64")
-				}
-				simI, err := jmespath.Search(fmt.Sprintf("cellular.sim.\"%d\"", int(s)), wanI)
-				if err != nil {
-					return nil, fmt.Errorf("failed to get sim wan status from json: %w", err)
-				}
-				// Do marshal/unmarshal to workaround interface{} hussle
-				buf, err := json.Marshal(simI)
-				if err != nil {
-					return nil, fmt.Errorf("failed to get wan status from json: %w", err)
-				}
-				sim := SIMGroupObj{}
-				err = json.Unmarshal(buf, &sim)
-				if err != nil {
-					return nil, fmt.Errorf("failed to get wan status from json: %w", err)
-				}
-				wan.Cellular.SIM = append(wan.Cellular.SIM, sim)
-			}
+	}
+
+	return WanStatus{}, false
+}
+
+// WanByName returns the first WAN connection with the name
+func WanByName(statuses []WanStatus, name string) (WanStatus, bool) {
+	for _, s := range statuses {
+		if s.Name == name {
+			return s, true
 		}
-		statuses = append(statuses, wan)
 	}
 
-	return statuses, nil
+	return WanStatus{}, false
 }
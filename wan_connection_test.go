@@ -2,6 +2,7 @@ package peplink
 
 import (
 	"context"
+	"encoding/json"
 	"log/slog"
 	"net/http"
 	"net/http/httptest"
@@ -11,6 +12,10 @@ import (
 	"github.com/stretchr/testify/require"
 )
 
+func ptr[T any](v T) *T {
+	return &v
+}
+
 func TestClient_StatusWanConnection(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -366,6 +371,7 @@ func TestClient_StatusWanConnection(t *testing.T) {
 			  }`,
 			[]WanStatus{
 				{
+					ID:           1,
 					Name:         "WAN 1",
 					StatusLed:    "red",
 					AsLan:        false,
@@ -376,34 +382,34 @@ func TestClient_StatusWanConnection(t *testing.T) {
 					Uptime:       0,
 					Type:         "ethernet",
 					VirtualType:  "ethernet",
-					Priority:     1,
+					Priority:     ptr(1),
 					Groupset:     0,
 					Ip:           "",
-					Mask:         0,
-					Gateway:      "",
-					Method:       "dhcp",
-					Mode:         "",
+					Mask:         nil,
+					Gateway:      nil,
+					Method:       ptr("dhcp"),
+					Mode:         ptr("NAT"),
 					Dns:          []string(nil),
 					AditionalIp:  []string(nil),
-					MTU:          1440,
-					MSS:          0,
-					Mac:          "",
-					Wireless: WifiInfo{SSID: "",
-						BSSID: "",
-						Signal: Signal{RSSI: 0,
-							SINR:     0,
-							SNR:      0,
-							ECIO:     0,
-							RSRP:     0,
-							RSRQ:     0,
-							Strength: 0},
+					MTU:          ptr(1440),
+					MSS:          nil,
+					Mac:          nil,
+					Wireless: WifiInfo{SSID: nil,
+						BSSID: nil,
+						Signal: Signal{RSSI: nil,
+							SINR:     nil,
+							SNR:      nil,
+							ECIO:     nil,
+							RSRP:     nil,
+							RSRQ:     nil,
+							Strength: nil},
 					},
 					Modem: ModemObj{Name: "",
 						VendorID:     0,
 						ProductID:    0,
 						Manufacturer: "",
 						Carrier: CarrierObj{Name: "",
-							Country: ""},
+							Country: nil},
 						SignalLevel: 0,
 						Network:     "",
 						MobileType:  "",
@@ -431,7 +437,7 @@ func TestClient_StatusWanConnection(t *testing.T) {
 							SpeedFusionConnect5gLTE: SpeedFusionConnect5gLTE{Active: false,
 								Iccid: ""},
 							Carrier: CarrierObj{Name: "",
-								Country: ""},
+								Country: nil},
 							CarrierAggregation: false,
 							SignalLevel:        0,
 							RAT:                []RatObj(nil),
@@ -465,7 +471,7 @@ func TestClient_StatusWanConnection(t *testing.T) {
 						SpeedFusionConnect5gLTE: SpeedFusionConnect5gLTE{Active: false,
 							Iccid: ""},
 						Carrier: CarrierObj{Name: "",
-							Country: ""},
+							Country: nil},
 						CarrierAggregation: false,
 						SignalLevel:        0,
 						RAT:                []RatObj(nil),
@@ -498,7 +504,7 @@ func TestClient_StatusWanConnection(t *testing.T) {
 						SpeedFusionConnect5gLTE: SpeedFusionConnect5gLTE{Active: false,
 							Iccid: ""},
 						Carrier: CarrierObj{Name: "",
-							Country: ""},
+							Country: nil},
 						CarrierAggregation: false,
 						SignalLevel:        0,
 						RAT:                []RatObj(nil),
@@ -517,6 +523,7 @@ func TestClient_StatusWanConnection(t *testing.T) {
 						Firmware: ""},
 				},
 				{
+					ID:           2,
 					Name:         "WAN 2",
 					StatusLed:    "red",
 					AsLan:        false,
@@ -527,33 +534,33 @@ func TestClient_StatusWanConnection(t *testing.T) {
 					Uptime:       0,
 					Type:         "ethernet",
 					VirtualType:  "ethernet",
-					Priority:     1,
+					Priority:     ptr(1),
 					Groupset:     0,
 					Ip:           "",
-					Mask:         0,
-					Gateway:      "",
-					Method:       "dhcp",
-					Mode:         "", Dns: []string(nil),
+					Mask:         nil,
+					Gateway:      nil,
+					Method:       ptr("dhcp"),
+					Mode:         ptr("NAT"), Dns: []string(nil),
 					AditionalIp: []string(nil),
-					MTU:         1440,
-					MSS:         0,
-					Mac:         "",
-					Wireless: WifiInfo{SSID: "",
-						BSSID: "",
-						Signal: Signal{RSSI: 0,
-							SINR:     0,
-							SNR:      0,
-							ECIO:     0,
-							RSRP:     0,
-							RSRQ:     0,
-							Strength: 0},
+					MTU:         ptr(1440),
+					MSS:         nil,
+					Mac:         nil,
+					Wireless: WifiInfo{SSID: nil,
+						BSSID: nil,
+						Signal: Signal{RSSI: nil,
+							SINR:     nil,
+							SNR:      nil,
+							ECIO:     nil,
+							RSRP:     nil,
+							RSRQ:     nil,
+							Strength: nil},
 					},
 					Modem: ModemObj{Name: "",
 						VendorID:     0,
 						ProductID:    0,
 						Manufacturer: "",
 						Carrier: CarrierObj{Name: "",
-							Country: ""},
+							Country: nil},
 						SignalLevel: 0,
 						Network:     "",
 						MobileType:  "",
@@ -582,7 +589,7 @@ func TestClient_StatusWanConnection(t *testing.T) {
 							SpeedFusionConnect5gLTE: SpeedFusionConnect5gLTE{Active: false,
 								Iccid: ""},
 							Carrier: CarrierObj{Name: "",
-								Country: ""},
+								Country: nil},
 							CarrierAggregation: false,
 							SignalLevel:        0,
 							RAT:                []RatObj(nil),
@@ -616,7 +623,7 @@ func TestClient_StatusWanConnection(t *testing.T) {
 						SpeedFusionConnect5gLTE: SpeedFusionConnect5gLTE{Active: false,
 							Iccid: ""},
 						Carrier: CarrierObj{Name: "",
-							Country: ""},
+							Country: nil},
 						CarrierAggregation: false,
 						SignalLevel:        0,
 						RAT:                []RatObj(nil),
@@ -649,7 +656,7 @@ func TestClient_StatusWanConnection(t *testing.T) {
 						SpeedFusionConnect5gLTE: SpeedFusionConnect5gLTE{Active: false,
 							Iccid: ""},
 						Carrier: CarrierObj{Name: "",
-							Country: ""},
+							Country: nil},
 						CarrierAggregation: false,
 						SignalLevel:        0,
 						RAT:                []RatObj(nil),
@@ -668,6 +675,7 @@ func TestClient_StatusWanConnection(t *testing.T) {
 						Firmware: ""},
 				},
 				{
+					ID:           3,
 					Name:         "Cellular 1",
 					StatusLed:    "green",
 					AsLan:        false,
@@ -678,34 +686,34 @@ func TestClient_StatusWanConnection(t *testing.T) {
 					Uptime:       3314261,
 					Type:         "cellular",
 					VirtualType:  "cellular",
-					Priority:     2,
+					Priority:     ptr(2),
 					Groupset:     0,
 					Ip:           "10.10.10.10",
-					Mask:         31,
-					Gateway:      "10.10.1.1",
-					Method:       "dhcp",
-					Mode:         "",
+					Mask:         ptr(31),
+					Gateway:      ptr("10.10.1.1"),
+					Method:       ptr("dhcp"),
+					Mode:         ptr("NAT"),
 					Dns: []string{"10.10.1.1",
 						"10.10.1.2"},
 					AditionalIp: []string(nil),
-					MTU:         1428,
-					MSS:         0,
-					Mac:         "",
-					Wireless: WifiInfo{SSID: "",
-						BSSID: "",
-						Signal: Signal{RSSI: 0,
-							SINR:     0,
-							SNR:      0,
-							ECIO:     0,
-							RSRP:     0,
-							RSRQ:     0,
-							Strength: 0},
+					MTU:         ptr(1428),
+					MSS:         nil,
+					Mac:         nil,
+					Wireless: WifiInfo{SSID: nil,
+						BSSID: nil,
+						Signal: Signal{RSSI: nil,
+							SINR:     nil,
+							SNR:      nil,
+							ECIO:     nil,
+							RSRP:     nil,
+							RSRQ:     nil,
+							Strength: nil},
 					},
 					Modem: ModemObj{Name: "",
 						VendorID:  0,
 						ProductID: 0, Manufacturer: "",
 						Carrier: CarrierObj{Name: "",
-							Country: ""},
+							Country: nil},
 						SignalLevel: 0,
 						Network:     "",
 						MobileType:  "",
@@ -734,7 +742,7 @@ func TestClient_StatusWanConnection(t *testing.T) {
 							SpeedFusionConnect5gLTE: SpeedFusionConnect5gLTE{Active: false,
 								Iccid: ""},
 							Carrier: CarrierObj{Name: "",
-								Country: ""},
+								Country: nil},
 							CarrierAggregation: false,
 							SignalLevel:        0,
 							RAT:                []RatObj(nil),
@@ -790,7 +798,7 @@ func TestClient_StatusWanConnection(t *testing.T) {
 						SpeedFusionConnect5gLTE: SpeedFusionConnect5gLTE{Active: false,
 							Iccid: "1111111111111"},
 						Carrier: CarrierObj{Name: "Carrier1",
-							Country: "Germany"},
+							Country: ptr("Germany")},
 						CarrierAggregation: false,
 						SignalLevel:        5,
 						RAT: []RatObj{
@@ -798,14 +806,16 @@ func TestClient_StatusWanConnection(t *testing.T) {
 								Name: "",
 								Band: []BandObj{
 									{
-										Name: "LTE Band 1 (2100 MHz)",
-										Signal: SignalObj{RSSI: -63,
-											SINR:     19.4,
-											SNR:      0,
-											ECIO:     0,
-											RSRP:     -90,
-											RSRQ:     -8,
-											Strength: 0},
+										Name:         "LTE Band 1 (2100 MHz)",
+										Channel:      100,
+										ChannelWidth: "20 MHz",
+										Signal: SignalObj{RSSI: ptr(-63),
+											SINR:     ptr(19.4),
+											SNR:      nil,
+											ECIO:     nil,
+											RSRP:     ptr(-90.0),
+											RSRQ:     ptr(-8.0),
+											Strength: nil},
 									},
 								},
 							},
@@ -839,7 +849,7 @@ func TestClient_StatusWanConnection(t *testing.T) {
 						SpeedFusionConnect5gLTE: SpeedFusionConnect5gLTE{Active: false,
 							Iccid: ""},
 						Carrier: CarrierObj{Name: "",
-							Country: ""},
+							Country: nil},
 						CarrierAggregation: false,
 						SignalLevel:        0,
 						RAT:                []RatObj(nil),
@@ -858,6 +868,7 @@ func TestClient_StatusWanConnection(t *testing.T) {
 						Firmware: ""},
 				},
 				{
+					ID:           4,
 					Name:         "Cellular 2",
 					StatusLed:    "green",
 					AsLan:        false,
@@ -868,35 +879,35 @@ func TestClient_StatusWanConnection(t *testing.T) {
 					Uptime:       330759,
 					Type:         "cellular",
 					VirtualType:  "cellular",
-					Priority:     2,
+					Priority:     ptr(2),
 					Groupset:     0,
 					Ip:           "10.10.10.11",
-					Mask:         31,
-					Gateway:      "10.10.12.13",
-					Method:       "dhcp",
-					Mode:         "",
+					Mask:         ptr(31),
+					Gateway:      ptr("10.10.12.13"),
+					Method:       ptr("dhcp"),
+					Mode:         ptr("NAT"),
 					Dns: []string{"10.10.12.13",
 						"10.10.12.11"},
 					AditionalIp: []string(nil),
-					MTU:         1428,
-					MSS:         0,
-					Mac:         "",
-					Wireless: WifiInfo{SSID: "",
-						BSSID: "",
-						Signal: Signal{RSSI: 0,
-							SINR:     0,
-							SNR:      0,
-							ECIO:     0,
-							RSRP:     0,
-							RSRQ:     0,
-							Strength: 0},
+					MTU:         ptr(1428),
+					MSS:         nil,
+					Mac:         nil,
+					Wireless: WifiInfo{SSID: nil,
+						BSSID: nil,
+						Signal: Signal{RSSI: nil,
+							SINR:     nil,
+							SNR:      nil,
+							ECIO:     nil,
+							RSRP:     nil,
+							RSRQ:     nil,
+							Strength: nil},
 					},
 					Modem: ModemObj{Name: "",
 						VendorID:     0,
 						ProductID:    0,
 						Manufacturer: "",
 						Carrier: CarrierObj{Name: "",
-							Country: ""},
+							Country: nil},
 						SignalLevel: 0,
 						Network:     "",
 						MobileType:  "",
@@ -925,7 +936,7 @@ func TestClient_StatusWanConnection(t *testing.T) {
 							SpeedFusionConnect5gLTE: SpeedFusionConnect5gLTE{Active: false,
 								Iccid: ""},
 							Carrier: CarrierObj{Name: "",
-								Country: ""},
+								Country: nil},
 							CarrierAggregation: false,
 							SignalLevel:        0,
 							RAT:                []RatObj(nil),
@@ -954,8 +965,11 @@ func TestClient_StatusWanConnection(t *testing.T) {
 								SimCardDetected: true,
 								Imsi:            "1111111111",
 								Iccid:           "22222222",
+								Mtn:             "3333333333",
 								AutoApn:         true,
 								Apn:             "wap.carreir2.de",
+								Username:        "user",
+								Password:        "pass",
 								BandwidthAllowanceMonitor: BandwidthAllowanceMonitorObj{Enable: false,
 									HasSMTP: false},
 							},
@@ -981,7 +995,7 @@ func TestClient_StatusWanConnection(t *testing.T) {
 						SpeedFusionConnect5gLTE: SpeedFusionConnect5gLTE{Active: false,
 							Iccid: "1111111111"},
 						Carrier: CarrierObj{Name: "Carrier2",
-							Country: "Germany"},
+							Country: ptr("Germany")},
 						CarrierAggregation: false,
 						SignalLevel:        5,
 						RAT: []RatObj{
@@ -989,14 +1003,16 @@ func TestClient_StatusWanConnection(t *testing.T) {
 								Name: "",
 								Band: []BandObj{
 									{
-										Name: "LTE Band 3 (1800 MHz)",
-										Signal: SignalObj{RSSI: -43,
-											SINR:     12.2,
-											SNR:      0,
-											ECIO:     0,
-											RSRP:     -76,
-											RSRQ:     -12.4,
-											Strength: 0},
+										Name:         "LTE Band 3 (1800 MHz)",
+										Channel:      1300,
+										ChannelWidth: "20 MHz",
+										Signal: SignalObj{RSSI: ptr(-43),
+											SINR:     ptr(12.2),
+											SNR:      nil,
+											ECIO:     nil,
+											RSRP:     ptr(-76.0),
+											RSRQ:     ptr(-12.4),
+											Strength: nil},
 									},
 								},
 							},
@@ -1012,8 +1028,9 @@ func TestClient_StatusWanConnection(t *testing.T) {
 							CellPlmn:    22222,
 							CellUtranID: 333333,
 							Tac:         1111},
-						Model:    "Model2",
-						Firmware: ""},
+						Manufacturer: "Example",
+						Model:        "Model2",
+						Firmware:     ""},
 					Gobi: GobiObj{RoamingStatus: RoamingObj{Code: 0,
 						Message: ""},
 						Network:       "",
@@ -1030,7 +1047,7 @@ func TestClient_StatusWanConnection(t *testing.T) {
 						SpeedFusionConnect5gLTE: SpeedFusionConnect5gLTE{Active: false,
 							Iccid: ""},
 						Carrier: CarrierObj{Name: "",
-							Country: ""},
+							Country: nil},
 						CarrierAggregation: false,
 						SignalLevel:        0,
 						RAT:                []RatObj(nil),
@@ -1049,6 +1066,7 @@ func TestClient_StatusWanConnection(t *testing.T) {
 						Firmware: ""},
 				},
 				{
+					ID:           5,
 					Name:         "USB",
 					StatusLed:    "empty",
 					AsLan:        false,
@@ -1059,34 +1077,34 @@ func TestClient_StatusWanConnection(t *testing.T) {
 					Uptime:       0,
 					Type:         "modem",
 					VirtualType:  "modem",
-					Priority:     2,
+					Priority:     ptr(2),
 					Groupset:     0,
 					Ip:           "",
-					Mask:         0,
-					Gateway:      "",
-					Method:       "ppp",
-					Mode:         "",
+					Mask:         nil,
+					Gateway:      nil,
+					Method:       ptr("ppp"),
+					Mode:         ptr("NAT"),
 					Dns:          []string(nil),
 					AditionalIp:  []string(nil),
-					MTU:          1428,
-					MSS:          0,
-					Mac:          "",
-					Wireless: WifiInfo{SSID: "",
-						BSSID: "",
-						Signal: Signal{RSSI: 0,
-							SINR:     0,
-							SNR:      0,
-							ECIO:     0,
-							RSRP:     0,
-							RSRQ:     0,
-							Strength: 0},
+					MTU:          ptr(1428),
+					MSS:          nil,
+					Mac:          nil,
+					Wireless: WifiInfo{SSID: nil,
+						BSSID: nil,
+						Signal: Signal{RSSI: nil,
+							SINR:     nil,
+							SNR:      nil,
+							ECIO:     nil,
+							RSRP:     nil,
+							RSRQ:     nil,
+							Strength: nil},
 					},
 					Modem: ModemObj{Name: "",
 						VendorID:     0,
 						ProductID:    0,
 						Manufacturer: "",
 						Carrier: CarrierObj{Name: "",
-							Country: ""},
+							Country: nil},
 						SignalLevel: 0,
 						Network:     "",
 						MobileType:  "",
@@ -1115,7 +1133,7 @@ func TestClient_StatusWanConnection(t *testing.T) {
 							SpeedFusionConnect5gLTE: SpeedFusionConnect5gLTE{Active: false,
 								Iccid: ""},
 							Carrier: CarrierObj{Name: "",
-								Country: ""},
+								Country: nil},
 							CarrierAggregation: false,
 							SignalLevel:        0,
 							RAT:                []RatObj(nil),
@@ -1149,7 +1167,7 @@ func TestClient_StatusWanConnection(t *testing.T) {
 						SpeedFusionConnect5gLTE: SpeedFusionConnect5gLTE{Active: false,
 							Iccid: ""},
 						Carrier: CarrierObj{Name: "",
-							Country: ""},
+							Country: nil},
 						CarrierAggregation: false,
 						SignalLevel:        0,
 						RAT:                []RatObj(nil),
@@ -1182,7 +1200,7 @@ func TestClient_StatusWanConnection(t *testing.T) {
 						SpeedFusionConnect5gLTE: SpeedFusionConnect5gLTE{Active: false,
 							Iccid: ""},
 						Carrier: CarrierObj{Name: "",
-							Country: ""},
+							Country: nil},
 						CarrierAggregation: false,
 						SignalLevel:        0,
 						RAT:                []RatObj(nil),
@@ -1201,6 +1219,7 @@ func TestClient_StatusWanConnection(t *testing.T) {
 						Firmware: ""},
 				},
 				{
+					ID:           6,
 					Name:         "Wi-Fi WAN on 2.4 GHz",
 					StatusLed:    "gray",
 					AsLan:        false,
@@ -1211,34 +1230,34 @@ func TestClient_StatusWanConnection(t *testing.T) {
 					Uptime:       0,
 					Type:         "wifi",
 					VirtualType:  "wifi",
-					Priority:     0,
+					Priority:     nil,
 					Groupset:     0,
 					Ip:           "",
-					Mask:         0,
-					Gateway:      "",
-					Method:       "unknown",
-					Mode:         "",
+					Mask:         nil,
+					Gateway:      nil,
+					Method:       ptr("unknown"),
+					Mode:         ptr("NAT"),
 					Dns:          []string(nil),
 					AditionalIp:  []string(nil),
-					MTU:          1500,
-					MSS:          0,
-					Mac:          "",
-					Wireless: WifiInfo{SSID: "",
-						BSSID: "",
-						Signal: Signal{RSSI: 0,
-							SINR:     0,
-							SNR:      0,
-							ECIO:     0,
-							RSRP:     0,
-							RSRQ:     0,
-							Strength: 0},
+					MTU:          ptr(1500),
+					MSS:          nil,
+					Mac:          nil,
+					Wireless: WifiInfo{SSID: nil,
+						BSSID: nil,
+						Signal: Signal{RSSI: nil,
+							SINR:     nil,
+							SNR:      nil,
+							ECIO:     nil,
+							RSRP:     nil,
+							RSRQ:     nil,
+							Strength: nil},
 					},
 					Modem: ModemObj{Name: "",
 						VendorID:     0,
 						ProductID:    0,
 						Manufacturer: "",
 						Carrier: CarrierObj{Name: "",
-							Country: ""},
+							Country: nil},
 						SignalLevel: 0,
 						Network:     "",
 						MobileType:  "",
@@ -1267,7 +1286,7 @@ func TestClient_StatusWanConnection(t *testing.T) {
 							SpeedFusionConnect5gLTE: SpeedFusionConnect5gLTE{Active: false,
 								Iccid: ""},
 							Carrier: CarrierObj{Name: "",
-								Country: ""},
+								Country: nil},
 							CarrierAggregation: false,
 							SignalLevel:        0,
 							RAT:                []RatObj(nil),
@@ -1301,7 +1320,7 @@ func TestClient_StatusWanConnection(t *testing.T) {
 						SpeedFusionConnect5gLTE: SpeedFusionConnect5gLTE{Active: false,
 							Iccid: ""},
 						Carrier: CarrierObj{Name: "",
-							Country: ""},
+							Country: nil},
 						CarrierAggregation: false,
 						SignalLevel:        0,
 						RAT:                []RatObj(nil),
@@ -1334,7 +1353,7 @@ func TestClient_StatusWanConnection(t *testing.T) {
 						SpeedFusionConnect5gLTE: SpeedFusionConnect5gLTE{Active: false,
 							Iccid: ""},
 						Carrier: CarrierObj{Name: "",
-							Country: ""},
+							Country: nil},
 						CarrierAggregation: false,
 						SignalLevel:        0,
 						RAT:                []RatObj(nil),
@@ -1353,6 +1372,7 @@ func TestClient_StatusWanConnection(t *testing.T) {
 						Firmware: ""},
 				},
 				{
+					ID:           7,
 					Name:         "Wi-Fi WAN on 5 GHz",
 					StatusLed:    "gray",
 					AsLan:        false,
@@ -1363,34 +1383,34 @@ func TestClient_StatusWanConnection(t *testing.T) {
 					Uptime:       0,
 					Type:         "wifi",
 					VirtualType:  "wifi",
-					Priority:     0,
+					Priority:     nil,
 					Groupset:     0,
 					Ip:           "",
-					Mask:         0,
-					Gateway:      "",
-					Method:       "unknown",
-					Mode:         "",
+					Mask:         nil,
+					Gateway:      nil,
+					Method:       ptr("unknown"),
+					Mode:         ptr("NAT"),
 					Dns:          []string(nil),
 					AditionalIp:  []string(nil),
-					MTU:          1500,
-					MSS:          0,
-					Mac:          "",
-					Wireless: WifiInfo{SSID: "",
-						BSSID: "",
-						Signal: Signal{RSSI: 0,
-							SINR:     0,
-							SNR:      0,
-							ECIO:     0,
-							RSRP:     0,
-							RSRQ:     0,
-							Strength: 0},
+					MTU:          ptr(1500),
+					MSS:          nil,
+					Mac:          nil,
+					Wireless: WifiInfo{SSID: nil,
+						BSSID: nil,
+						Signal: Signal{RSSI: nil,
+							SINR:     nil,
+							SNR:      nil,
+							ECIO:     nil,
+							RSRP:     nil,
+							RSRQ:     nil,
+							Strength: nil},
 					},
 					Modem: ModemObj{Name: "",
 						VendorID:     0,
 						ProductID:    0,
 						Manufacturer: "",
 						Carrier: CarrierObj{Name: "",
-							Country: ""},
+							Country: nil},
 						SignalLevel: 0,
 						Network:     "",
 						MobileType:  "",
@@ -1419,7 +1439,7 @@ func TestClient_StatusWanConnection(t *testing.T) {
 							SpeedFusionConnect5gLTE: SpeedFusionConnect5gLTE{Active: false,
 								Iccid: ""},
 							Carrier: CarrierObj{Name: "",
-								Country: ""},
+								Country: nil},
 							CarrierAggregation: false,
 							SignalLevel:        0,
 							RAT:                []RatObj(nil),
@@ -1453,7 +1473,7 @@ func TestClient_StatusWanConnection(t *testing.T) {
 						SpeedFusionConnect5gLTE: SpeedFusionConnect5gLTE{Active: false,
 							Iccid: ""},
 						Carrier: CarrierObj{Name: "",
-							Country: ""},
+							Country: nil},
 						CarrierAggregation: false,
 						SignalLevel:        0,
 						RAT:                []RatObj(nil),
@@ -1486,7 +1506,7 @@ func TestClient_StatusWanConnection(t *testing.T) {
 						SpeedFusionConnect5gLTE: SpeedFusionConnect5gLTE{Active: false,
 							Iccid: ""},
 						Carrier: CarrierObj{Name: "",
-							Country: ""},
+							Country: nil},
 						CarrierAggregation: false,
 						SignalLevel:        0,
 						RAT:                []RatObj(nil),
@@ -1505,6 +1525,7 @@ func TestClient_StatusWanConnection(t *testing.T) {
 						Firmware: ""},
 				},
 				{
+					ID:           8,
 					Name:         "VLAN WAN 1",
 					StatusLed:    "gray",
 					AsLan:        false,
@@ -1515,34 +1536,34 @@ func TestClient_StatusWanConnection(t *testing.T) {
 					Uptime:       0,
 					Type:         "wovlan",
 					VirtualType:  "wovlan",
-					Priority:     0,
+					Priority:     nil,
 					Groupset:     0,
 					Ip:           "",
-					Mask:         0,
-					Gateway:      "",
-					Method:       "dhcp",
-					Mode:         "",
+					Mask:         nil,
+					Gateway:      nil,
+					Method:       ptr("dhcp"),
+					Mode:         ptr("NAT"),
 					Dns:          []string(nil),
 					AditionalIp:  []string(nil),
-					MTU:          0,
-					MSS:          0,
-					Mac:          "",
-					Wireless: WifiInfo{SSID: "",
-						BSSID: "",
-						Signal: Signal{RSSI: 0,
-							SINR:     0,
-							SNR:      0,
-							ECIO:     0,
-							RSRP:     0,
-							RSRQ:     0,
-							Strength: 0},
+					MTU:          nil,
+					MSS:          nil,
+					Mac:          nil,
+					Wireless: WifiInfo{SSID: nil,
+						BSSID: nil,
+						Signal: Signal{RSSI: nil,
+							SINR:     nil,
+							SNR:      nil,
+							ECIO:     nil,
+							RSRP:     nil,
+							RSRQ:     nil,
+							Strength: nil},
 					},
 					Modem: ModemObj{Name: "",
 						VendorID:     0,
 						ProductID:    0,
 						Manufacturer: "",
 						Carrier: CarrierObj{Name: "",
-							Country: ""},
+							Country: nil},
 						SignalLevel: 0,
 						Network:     "",
 						MobileType:  "",
@@ -1571,7 +1592,7 @@ func TestClient_StatusWanConnection(t *testing.T) {
 							SpeedFusionConnect5gLTE: SpeedFusionConnect5gLTE{Active: false,
 								Iccid: ""},
 							Carrier: CarrierObj{Name: "",
-								Country: ""},
+								Country: nil},
 							CarrierAggregation: false,
 							SignalLevel:        0,
 							RAT:                []RatObj(nil),
@@ -1605,7 +1626,7 @@ func TestClient_StatusWanConnection(t *testing.T) {
 						SpeedFusionConnect5gLTE: SpeedFusionConnect5gLTE{Active: false,
 							Iccid: ""},
 						Carrier: CarrierObj{Name: "",
-							Country: ""},
+							Country: nil},
 						CarrierAggregation: false,
 						SignalLevel:        0,
 						RAT:                []RatObj(nil),
@@ -1638,7 +1659,7 @@ func TestClient_StatusWanConnection(t *testing.T) {
 						SpeedFusionConnect5gLTE: SpeedFusionConnect5gLTE{Active: false,
 							Iccid: ""},
 						Carrier: CarrierObj{Name: "",
-							Country: ""},
+							Country: nil},
 						CarrierAggregation: false,
 						SignalLevel:        0,
 						RAT:                []RatObj(nil),
@@ -1661,6 +1682,7 @@ func TestClient_StatusWanConnection(t *testing.T) {
 		},
 		// TODO: Add test cases.
 	}
+	strict(t)
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			srv := httptest.NewServer(
@@ -1688,3 +1710,179 @@ func TestClient_StatusWanConnection(t *testing.T) {
 		})
 	}
 }
+
+func TestWanLookup(t *testing.T) {
+	statuses := []WanStatus{
+		{ID: 1, Name: "WAN 1"},
+		{ID: 3, Name: "Cellular 1"},
+	}
+
+	got, ok := WanByID(statuses, 3)
+	require.True(t, ok)
+	require.Equal(t, "Cellular 1", got.Name)
+
+	_, ok = WanByID(statuses, 2)
+	require.False(t, ok)
+
+	got, ok = WanByName(statuses, "WAN 1")
+	require.True(t, ok)
+	require.Equal(t, 1, got.ID)
+
+	_, ok = WanByName(statuses, "WAN 2")
+	require.False(t, ok)
+}
+
+func TestClient_StatusWanConnectionQuery(t *testing.T) {
+	tests := []struct {
+		name      string
+		opts      []WanStatusOption
+		wantQuery map[string][]string
+		want      []WanStatus
+	}{
+		{"all",
+			nil,
+			map[string][]string{},
+			[]WanStatus{{ID: 2, Name: "WAN 2"}},
+		},
+		{"ids",
+			[]WanStatusOption{WithWanIDs(2, 3)},
+			map[string][]string{"id": {"2 3"}},
+			[]WanStatus{{ID: 2, Name: "WAN 2"}},
+		},
+		{"ids and lite",
+			[]WanStatusOption{WithWanIDs(2), WithWanLite()},
+			map[string][]string{"id": {"2"}, "lite": {"yes"}},
+			[]WanStatus{{ID: 2, Name: "WAN 2", Lite: true}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+				require.Equal(t, tt.wantQuery, map[string][]string(r.URL.Query()))
+				w.Header().Set("Content-Type", "application/json")
+				w.Write([]byte(`{"stat": "ok", "response": {"2": {"name": "WAN 2"}, "order": [2]}}`))
+			})
+
+			got, err := c.StatusWanConnection(context.Background(), tt.opts...)
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestWanStatus_absentFields(t *testing.T) {
+	var got OrderedMap[WanStatus]
+	err := json.Unmarshal([]byte(`{
+		"1": {"name": "WAN 1", "enable": false, "wireless": {"signal": {"strength": 0}}},
+		"2": {"name": "WAN 2", "enable": true, "priority": 1, "mtu": 1500, "mss": 1460},
+		"order": [1, 2]
+	}`), &got)
+	require.NoError(t, err)
+
+	disabled, enabled := got[0].Value, got[1].Value
+	require.Nil(t, disabled.Priority, "disabled WAN has no priority")
+	require.Nil(t, disabled.MTU, "auto MTU")
+	require.Nil(t, disabled.MSS, "auto MSS")
+	require.Nil(t, disabled.Wireless.Signal.RSSI)
+	require.Equal(t, ptr(0.0), disabled.Wireless.Signal.Strength, "reported zero is kept")
+
+	require.Equal(t, ptr(1), enabled.Priority)
+	require.Equal(t, ptr(1500), enabled.MTU)
+	require.Equal(t, ptr(1460), enabled.MSS)
+}
+
+func TestClient_StatusWanConnectionLite(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "yes", r.URL.Query().Get("lite"))
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{
+				"stat": "ok",
+				"response": {
+				  "1": {
+					"name": "WAN 1",
+					"enable": true,
+					"locked": false,
+					"statusLed": "green",
+					"message": "Connected",
+					"uptime": 5862,
+					"type": "ethernet",
+					"virtualType": "ethernet",
+					"priority": 1,
+					"groupset": 1,
+					"ip": "10.10.0.2"
+				  },
+				  "2": {
+					"name": "Mobile Internet",
+					"enable": true,
+					"locked": false,
+					"statusLed": "green",
+					"message": "Connected",
+					"uptime": 5840,
+					"type": "cellular",
+					"virtualType": "cellular",
+					"priority": 2,
+					"groupset": 1,
+					"ip": "10.10.1.2",
+					"cellular": {
+					  "network": "LTE",
+					  "mobileType": "LTE",
+					  "carrier": {
+						"name": "Carrier1"
+					  },
+					  "signalLevel": 4,
+					  "signal": {
+						"rssi": -65,
+						"rsrp": -95
+					  }
+					}
+				  },
+				  "3": {
+					"name": "Wi-Fi WAN",
+					"enable": true,
+					"locked": false,
+					"statusLed": "green",
+					"message": "Connected",
+					"uptime": 120,
+					"type": "wireless",
+					"virtualType": "wireless",
+					"priority": 3,
+					"groupset": 1,
+					"ip": "192.168.1.20",
+					"wireless": {
+					  "signal": {
+						"strength": 70
+					  }
+					}
+				  },
+				  "order": [1, 2, 3]
+				}
+			}`))
+	})
+
+	got, err := c.StatusWanConnection(context.Background(), WithWanLite())
+	require.NoError(t, err)
+	require.Len(t, got, 3)
+
+	for _, wan := range got {
+		require.True(t, wan.Lite)
+		require.Nil(t, wan.Mask, wan.Name)
+		require.Nil(t, wan.Gateway, wan.Name)
+		require.Nil(t, wan.Method, wan.Name)
+		require.Nil(t, wan.Mode, wan.Name)
+		require.Nil(t, wan.Dns, wan.Name)
+		require.Nil(t, wan.AditionalIp, wan.Name)
+		require.Nil(t, wan.MTU, wan.Name)
+		require.Nil(t, wan.MSS, wan.Name)
+		require.Nil(t, wan.Mac, wan.Name)
+		require.NotEmpty(t, wan.Ip, "ip is reported in the lite mode")
+	}
+
+	cellular := got[1]
+	require.Equal(t, "Carrier1", cellular.Cellular.Carrier.Name)
+	require.Nil(t, cellular.Cellular.Carrier.Country)
+
+	wifi := got[2]
+	require.Nil(t, wifi.Wireless.SSID)
+	require.Nil(t, wifi.Wireless.BSSID)
+	require.Equal(t, ptr(70.0), wifi.Wireless.Signal.Strength)
+}